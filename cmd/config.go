@@ -0,0 +1,248 @@
+// Package cmd
+// Author: Egor Pristavka <e@veverse.com>
+// Copyright © 2023 LE7EL AS
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+const envPrefix = "WEB_HELPER_"
+
+// settingSources records where the effective value of every flag comes from.
+var settingSources = map[string]string{}
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+	Long: `Settings are read from the config file, WEB_HELPER_* environment variables and flags, in the increasing
+order of precedence. The config file is YAML or TOML (by the .toml extension), its keys are the flag names:
+
+  hl: de
+  timeout: 15s
+  proxy: [socks5://10.0.0.1:1080, socks5://10.0.0.2:1080]
+  output: json-pretty
+
+Settings of a single command, like output of yt, apply to every command having the flag, see config show yt.
+
+The environment variables are the flag names in upper snake case, e.g. WEB_HELPER_HL=de or
+WEB_HELPER_PROXY=socks5://10.0.0.1:1080,socks5://10.0.0.2:1080.
+
+The config file is --config, WEB_HELPER_CONFIG or config.yaml/config.toml in the web-helper user config directory.`,
+}
+
+// configShowCmd represents the config show command
+var configShowCmd = &cobra.Command{
+	Use:   "show [command]",
+	Short: "Print the effective configuration",
+	Long: `Print the effective configuration merged from the defaults, the config file, the environment and flags.
+
+The global settings are printed by default, name a command to include its own settings, e.g. config show yt.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target := cmd
+		if len(args) > 0 {
+			found, rest, err := cmd.Root().Find(args)
+			if err != nil || len(rest) > 0 || found == cmd.Root() {
+				return fmt.Errorf("unknown command %q", strings.Join(args, " "))
+			}
+			// The command didn't run, merge the global flags into its flags and load them.
+			if err := found.ParseFlags(nil); err != nil {
+				return err
+			}
+			if err := loadConfig(found); err != nil {
+				return err
+			}
+			target = found
+		}
+
+		mapping := &yaml.Node{Kind: yaml.MappingNode}
+		target.Flags().VisitAll(func(flag *pflag.Flag) {
+			if flag.Name == "help" {
+				return
+			}
+
+			value := &yaml.Node{}
+			if err := value.Encode(flagValue(flag)); err != nil {
+				return
+			}
+			key := &yaml.Node{Kind: yaml.ScalarNode, Value: flag.Name, LineComment: settingSources[flag.Name]}
+			mapping.Content = append(mapping.Content, key, value)
+		})
+
+		encoder := yaml.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent(2)
+		if err := encoder.Encode(mapping); err != nil {
+			return err
+		}
+		return encoder.Close()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+}
+
+// flagValue returns the flag value as a plain value for printing.
+func flagValue(flag *pflag.Flag) interface{} {
+	if slice, ok := flag.Value.(pflag.SliceValue); ok {
+		return slice.GetSlice()
+	}
+	switch flag.Value.Type() {
+	case "int":
+		value, _ := strconv.Atoi(flag.Value.String())
+		return value
+	case "float64":
+		value, _ := strconv.ParseFloat(flag.Value.String(), 64)
+		return value
+	case "bool":
+		value, _ := strconv.ParseBool(flag.Value.String())
+		return value
+	}
+	return flag.Value.String()
+}
+
+// envName converts a flag name to its environment variable, e.g. utcOffsetMinutes to
+// WEB_HELPER_UTC_OFFSET_MINUTES.
+func envName(flagName string) string {
+	var builder strings.Builder
+	builder.WriteString(envPrefix)
+	for i, r := range flagName {
+		if unicode.IsUpper(r) && i > 0 {
+			builder.WriteByte('_')
+		}
+		builder.WriteRune(unicode.ToUpper(r))
+	}
+	return builder.String()
+}
+
+// configFile returns the config file to load, empty if there is none.
+func configFile(cmd *cobra.Command) string {
+	if path, _ := cmd.Flags().GetString("config"); path != "" {
+		return path
+	}
+	if path := os.Getenv(envPrefix + "CONFIG"); path != "" {
+		return path
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	for _, name := range []string{"config.yaml", "config.yml", "config.toml"} {
+		path := filepath.Join(dir, "web-helper", name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+func readConfigFile(path string) (map[string]interface{}, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := map[string]interface{}{}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = toml.Unmarshal(content, &settings)
+	} else {
+		err = yaml.Unmarshal(content, &settings)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+	return settings, nil
+}
+
+// configValue converts a config file value to the flag syntax.
+func configValue(value interface{}) string {
+	if list, ok := value.([]interface{}); ok {
+		items := make([]string, 0, len(list))
+		for _, item := range list {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+// loadConfig sets the flags of the command not given on the command line from the
+// environment and the config file.
+func loadConfig(cmd *cobra.Command) error {
+	settings := map[string]interface{}{}
+	path := configFile(cmd)
+	if path != "" {
+		var err error
+		if settings, err = readConfigFile(path); err != nil {
+			return err
+		}
+	}
+
+	var errs []string
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		settingSources[flag.Name] = "default"
+		if flag.Changed {
+			settingSources[flag.Name] = "flag"
+			return
+		}
+
+		if value, ok := os.LookupEnv(envName(flag.Name)); ok {
+			if err := flag.Value.Set(value); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", envName(flag.Name), err))
+			}
+			settingSources[flag.Name] = "env " + envName(flag.Name)
+			return
+		}
+
+		if value, ok := settings[flag.Name]; ok {
+			if err := flag.Value.Set(configValue(value)); err != nil {
+				errs = append(errs, fmt.Sprintf("%s in %s: %v", flag.Name, path, err))
+			}
+			settingSources[flag.Name] = "file " + path
+		}
+	})
+
+	unknown := []string{}
+	for key := range settings {
+		if !isCommandFlag(cmd.Root(), key) {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		errs = append(errs, fmt.Sprintf("unknown setting %s in %s", key, path))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return nil
+}
+
+// isCommandFlag reports whether any command has a flag with the name, so settings of
+// other commands in the shared config file are not reported as unknown.
+func isCommandFlag(cmd *cobra.Command, name string) bool {
+	if cmd.LocalFlags().Lookup(name) != nil {
+		return true
+	}
+	for _, child := range cmd.Commands() {
+		if isCommandFlag(child, name) {
+			return true
+		}
+	}
+	return false
+}
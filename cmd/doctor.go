@@ -0,0 +1,53 @@
+// Package cmd
+// Author: Egor Pristavka <e@veverse.com>
+// Copyright © 2023 LE7EL AS
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"web-helper/internal"
+)
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the YT resolution end to end",
+	Long: `Resolve a canary video end to end and report the outcome of every step, including the schema drift of
+the player response: unknown fields are listed with +, missing fields with -.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		videoId, _ := cmd.Flags().GetString("videoId")
+		strict, _ := cmd.Flags().GetBool("strict")
+
+		report := internal.DefaultClient.Doctor(cmd.Context(), videoId)
+
+		for _, check := range report.Checks {
+			status := "OK  "
+			if !check.OK {
+				status = "FAIL"
+			}
+			cmd.Printf("%s %-16s %-8s %s\n", status, check.Name, check.Duration.Round(time.Millisecond), check.Details)
+		}
+
+		if report.Drift != nil && !report.Drift.IsEmpty() {
+			cmd.Printf("\nSchema drift of the player response:\n%s", report.Drift)
+		}
+
+		if !report.OK() {
+			return fmt.Errorf("doctor found problems with %s", videoId)
+		}
+		if strict && report.Drift != nil && !report.Drift.IsEmpty() {
+			return fmt.Errorf("the player response drifted from the model")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().StringP("videoId", "v", internal.DefaultCanaryVideoId, "The canary video ID")
+	doctorCmd.Flags().Bool("strict", false, "Fail when the player response drifted from the model")
+}
@@ -0,0 +1,39 @@
+// Package cmd
+// Author: Egor Pristavka <e@veverse.com>
+// Copyright © 2023 LE7EL AS
+package cmd
+
+import (
+	"net"
+
+	"github.com/spf13/cobra"
+	"web-helper/internal"
+)
+
+// fakeRedisCmd represents the fake-redis command
+var fakeRedisCmd = &cobra.Command{
+	Use:   "fake-redis",
+	Short: "Serve a local stand-in for a Redis cache",
+	Long: `Serve the subset of the Redis protocol used by the Redis cache backend, keeping the values in memory.
+
+Point the tool at the stand-in with --cache redis://<addr>.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		password, _ := cmd.Flags().GetString("password")
+
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return err
+		}
+
+		cmd.Printf("Serving Redis on %s\n", addr)
+		return (&internal.FakeRedis{Password: password}).Serve(listener)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fakeRedisCmd)
+
+	fakeRedisCmd.Flags().StringP("addr", "a", ":6379", "The address to listen on")
+	fakeRedisCmd.Flags().String("password", "", "The password required by AUTH, empty for none")
+}
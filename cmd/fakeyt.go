@@ -0,0 +1,39 @@
+// Package cmd
+// Author: Egor Pristavka <e@veverse.com>
+// Copyright © 2023 LE7EL AS
+package cmd
+
+import (
+	"net/http"
+
+	"github.com/spf13/cobra"
+	"web-helper/internal"
+)
+
+// fakeYtCmd represents the fake-yt command
+var fakeYtCmd = &cobra.Command{
+	Use:   "fake-yt",
+	Short: "Serve a fake YT backend from fixtures",
+	Long: `Serve the YT player endpoint and googlevideo-style media URLs from a fixture directory for offline testing.
+
+Each video is a subdirectory of the fixture directory named by its ID, containing player.json with the player
+response, <itag>.<ext> media files and an optional scenario.json, e.g. {"playerStatusCode": 429},
+{"mediaStatusCode": 403} or {"expiresInSeconds": 5}. Unplayable videos such as LOGIN_REQUIRED are
+reproduced by their player.json playabilityStatus.
+
+Point the tool at the fake backend with --baseUrl http://<addr>/youtubei/v1.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		dir, _ := cmd.Flags().GetString("dir")
+
+		cmd.Printf("Serving fixtures from %s on %s\n", dir, addr)
+		return http.ListenAndServe(addr, &internal.FakeYT{Dir: dir})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fakeYtCmd)
+
+	fakeYtCmd.Flags().StringP("addr", "a", ":8090", "The address to listen on")
+	fakeYtCmd.Flags().StringP("dir", "d", "fixtures", "The fixture directory")
+}
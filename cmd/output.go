@@ -0,0 +1,270 @@
+// Package cmd
+// Author: Egor Pristavka <e@veverse.com>
+// Copyright © 2023 LE7EL AS
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// outputFormats lists the formats accepted by the --output flag.
+var outputFormats = []string{"json", "json-pretty", "yaml", "csv", "table", "template"}
+
+// printer renders command results in the format chosen with the --output, --fields and
+// --template flags. Batch results are rendered as a list, single results as a value.
+type printer struct {
+	format   string
+	fields   []string
+	template *template.Template
+}
+
+func newPrinter(format string, fields string, templateText string) (*printer, error) {
+	p := &printer{format: format}
+
+	known := false
+	for _, outputFormat := range outputFormats {
+		known = known || outputFormat == format
+	}
+	if !known {
+		return nil, fmt.Errorf("unknown output: %s (expected one of %s)", format, strings.Join(outputFormats, ", "))
+	}
+
+	for _, field := range strings.Split(fields, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			p.fields = append(p.fields, field)
+		}
+	}
+
+	if format == "template" {
+		if templateText == "" {
+			return nil, fmt.Errorf("--output template requires --template")
+		}
+		parsed, err := template.New("output").Parse(templateText)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %v", err)
+		}
+		p.template = parsed
+	}
+
+	if (format == "csv" || format == "table") && len(p.fields) == 0 {
+		return nil, fmt.Errorf("--output %s requires --fields", format)
+	}
+
+	return p, nil
+}
+
+// print writes the results; batch selects between a list and a single value output.
+func (p *printer) print(w io.Writer, results []interface{}, batch bool) error {
+	switch p.format {
+	case "template":
+		return p.printTemplate(w, results)
+	case "csv":
+		return p.printCSV(w, results)
+	case "table":
+		return p.printTable(w, results)
+	}
+
+	values := make([]interface{}, 0, len(results))
+	for _, result := range results {
+		value, err := p.project(result)
+		if err != nil {
+			return err
+		}
+		values = append(values, value)
+	}
+
+	var output interface{} = values
+	if !batch && len(values) == 1 {
+		output = values[0]
+	}
+
+	switch p.format {
+	case "json-pretty":
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(output)
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(output); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(output)
+}
+
+// project returns the generic JSON value of the result, reduced to the selected fields.
+func (p *printer) project(result interface{}) (interface{}, error) {
+	value, err := toGeneric(result)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.fields) == 0 {
+		return value, nil
+	}
+
+	projected := map[string]interface{}{}
+	for _, field := range p.fields {
+		projected[field] = lookupField(value, field)
+	}
+	return projected, nil
+}
+
+func (p *printer) printTemplate(w io.Writer, results []interface{}) error {
+	for _, result := range results {
+		if err := p.template.Execute(w, result); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *printer) rows(results []interface{}) ([][]string, error) {
+	rows := make([][]string, 0, len(results))
+	for _, result := range results {
+		value, err := toGeneric(result)
+		if err != nil {
+			return nil, err
+		}
+
+		row := make([]string, 0, len(p.fields))
+		for _, field := range p.fields {
+			row = append(row, formatCell(lookupField(value, field)))
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (p *printer) printCSV(w io.Writer, results []interface{}) error {
+	rows, err := p.rows(results)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(p.fields); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func (p *printer) printTable(w io.Writer, results []interface{}) error {
+	rows, err := p.rows(results)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	headers := make([]string, 0, len(p.fields))
+	for _, field := range p.fields {
+		headers = append(headers, strings.ToUpper(field))
+	}
+	fmt.Fprintln(writer, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		cells := make([]string, 0, len(row))
+		for _, cell := range row {
+			// Tabs and newlines would break the table layout.
+			cells = append(cells, strings.NewReplacer("\t", " ", "\n", " ").Replace(cell))
+		}
+		fmt.Fprintln(writer, strings.Join(cells, "\t"))
+	}
+	return writer.Flush()
+}
+
+// toGeneric converts the result to maps and slices keyed by the JSON field names.
+func toGeneric(result interface{}) (interface{}, error) {
+	serialized, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(serialized, &value); err != nil {
+		return nil, err
+	}
+	return integralNumbers(value), nil
+}
+
+// integralNumbers converts whole float64 numbers to int64, so they are not rendered in
+// exponent notation.
+func integralNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = integralNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = integralNumbers(item)
+		}
+	}
+	return value
+}
+
+// lookupField walks a dotted path of JSON field names and list indices,
+// e.g. streamingData.formats.0.url. Missing fields yield nil.
+func lookupField(value interface{}, path string) interface{} {
+	for _, key := range strings.Split(path, ".") {
+		switch current := value.(type) {
+		case map[string]interface{}:
+			value = current[key]
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(current) {
+				return nil
+			}
+			value = current[index]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+func formatCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	serialized, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(serialized)
+}
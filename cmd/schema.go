@@ -0,0 +1,30 @@
+// Package cmd
+// Author: Egor Pristavka <e@veverse.com>
+// Copyright © 2023 LE7EL AS
+package cmd
+
+import (
+	"encoding/json"
+
+	"github.com/spf13/cobra"
+	"web-helper/internal"
+)
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the media descriptor JSON Schema",
+	Long:  `Print the JSON Schema of the media descriptor returned by yt --output descriptor and the serve API.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		serializedSchema, err := json.MarshalIndent(internal.DescriptorSchema(), "", "  ")
+		if err != nil {
+			return
+		}
+
+		cmd.Println(string(serializedSchema))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,215 @@
+// Package cmd
+// Author: Egor Pristavka <e@veverse.com>
+// Copyright © 2023 LE7EL AS
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"web-helper/internal"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the HTTP API",
+	Long: `Serve the HTTP API resolving YT videos for clients.
+
+GET /v1/resolve?videoId=<id>&select=<criteria>&output=descriptor|raw returns the media descriptor by default.
+Unplayable videos get 422 with the error and the reason of their playability, the raw output is returned as is.
+GET /v1/schema returns the JSON Schema of the media descriptor.
+GET /metrics returns the metrics in the Prometheus text format, also served without authentication on --metricsAddr.
+GET|HEAD /v1/stream?videoId=<id>&itag=<itag> streams the media of the format through the helper, with Range support.
+Unplayable videos get 422 there too.
+With --streamKey the stream endpoint requires the token=<token> parameter issued in the descriptor stream URLs.
+GET /v1/manifest?videoId=<id>&type=hls|dash returns the manifest of a live stream, shared through --cache.
+
+GET /v1/watch upgrades to a WebSocket on which clients send {"type": "subscribe", "videoId": "<id>", "select": "<criteria>"}
+or {"type": "unsubscribe", "videoId": "<id>"}. The server pushes {"type": "descriptor", "videoId": "<id>", "descriptor": {...}}
+on subscription then again before the stream URLs expire, or {"type": "error", "videoId": "<id>", "error": {...}}.
+
+GET /v1/room?roomId=<id>&name=<name> joins a playback room over a WebSocket, the first member is the host. Members send
+{"type": "load", "videoId": "<id>", "select": "<criteria>"}, play, pause, {"type": "seek", "position": <seconds>},
+{"type": "host", "memberId": "<id>"} and {"type": "open", "open": true} to let everyone control the playback.
+They get the room state on every change, {"type": "ping", "clientTime": <ms>} is answered with the server clock and
+{"type": "position", "position": <seconds>} with a drift hint to catch up: none, rate or seek.
+
+The WebSocket endpoints accept pages from the host of the server and from --allowedOrigins, native clients send no
+origin. Browsers can't set the headers of the handshake, so they offer the credential as a subprotocol next to
+web-helper, new WebSocket(url, ["web-helper", "bearer.<credential>"]), and the server selects web-helper. Such
+credentials are limited to the characters of a JWT: letters, digits, -, _ and dots.
+
+With --grpc the WebHelper gRPC API of proto/webhelper/v1/webhelper.proto (Resolve, Search, Captions, LiveWatch) is
+also served, authenticated like the HTTP API with the x-api-key or authorization metadata. With --proxyMedia the
+descriptors point to the stream endpoint at --publicUrl.
+
+With --apiKeys, --jwks or --jwtSecret every request must send an API key or a JWT as Authorization: Bearer <credential>
+or X-API-Key: <key>. The API keys file is a JSON array:
+
+  [{"key": "<secret>", "name": "unity", "limits": {"ratePerSecond": 5, "burst": 20, "dailyQuota": 10000}}]
+
+Callers without limits of their own get --rateLimit, --burst and --dailyQuota.
+
+SIGINT and SIGTERM stop the server, waiting up to --shutdownTimeout for the requests in flight, then flush the
+pending spans to --otlpEndpoint.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+
+		server := internal.NewServer(internal.DefaultClient)
+		server.ProxyMedia, _ = cmd.Flags().GetBool("proxyMedia")
+		server.PublicURL, _ = cmd.Flags().GetString("publicUrl")
+		server.AllowedOrigins, _ = cmd.Flags().GetStringSlice("allowedOrigins")
+
+		streamKeys, _ := cmd.Flags().GetStringSlice("streamKey")
+		if len(streamKeys) > 0 {
+			tokens := &internal.StreamTokens{}
+			tokens.TTL, _ = cmd.Flags().GetDuration("streamTokenTtl")
+			for _, value := range streamKeys {
+				key, err := internal.ParseStreamKey(value)
+				if err != nil {
+					return err
+				}
+				tokens.Keys = append(tokens.Keys, key)
+			}
+			server.Tokens = tokens
+		}
+
+		auth, err := newAuthenticator(cmd)
+		if err != nil {
+			return err
+		}
+		server.Auth = auth
+
+		if metricsAddr, _ := cmd.Flags().GetString("metricsAddr"); metricsAddr != "" {
+			go func() {
+				cmd.Printf("Serving metrics on %s\n", metricsAddr)
+				if err := http.ListenAndServe(metricsAddr, internal.Metrics); err != nil {
+					cmd.PrintErrf("metrics server failed: %v\n", err)
+				}
+			}()
+		}
+
+		// Stopped by the signals rather than the process killed, so the spans get flushed.
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		var grpcServer *grpc.Server
+		if grpcAddr, _ := cmd.Flags().GetString("grpc"); grpcAddr != "" {
+			listener, err := net.Listen("tcp", grpcAddr)
+			if err != nil {
+				return err
+			}
+			grpcServer, err = internal.NewGRPCServer(server)
+			if err != nil {
+				return err
+			}
+			go func() {
+				cmd.Printf("Serving gRPC on %s\n", grpcAddr)
+				if err := grpcServer.Serve(listener); err != nil {
+					cmd.PrintErrf("gRPC server failed: %v\n", err)
+				}
+			}()
+		}
+
+		httpServer := &http.Server{Addr: addr, Handler: server}
+		served := make(chan error, 1)
+		go func() {
+			served <- httpServer.ListenAndServe()
+		}()
+		cmd.Printf("Listening on %s\n", addr)
+
+		select {
+		case err := <-served:
+			return errors.Join(err, stopTracing(context.Background()))
+		case <-ctx.Done():
+		}
+		stop()
+
+		cmd.Println("Shutting down")
+		shutdownTimeout, _ := cmd.Flags().GetDuration("shutdownTimeout")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		// The WebSocket and gRPC streams only end with the server, they aren't waited for.
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
+		err = httpServer.Shutdown(shutdownCtx)
+		return errors.Join(err, stopTracing(context.Background()))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringP("addr", "a", ":8080", "The address to listen on")
+	serveCmd.Flags().Bool("proxyMedia", false, "Point the descriptor streams to /v1/stream so clients never fetch googlevideo directly")
+	serveCmd.Flags().String("publicUrl", "", "The base URL clients reach the server at, e.g. https://helper.example.com, for the stream URLs, required by --grpc with --proxyMedia")
+	serveCmd.Flags().StringSlice("allowedOrigins", nil, "The origins of the pages allowed to open the WebSocket endpoints, e.g. https://world.example.com, * for any")
+	serveCmd.Flags().StringSlice("streamKey", nil, "The <id>:<secret> keys signing the stream tokens, the first one signs, all verify, repeat to rotate keys")
+	serveCmd.Flags().Duration("streamTokenTtl", time.Hour, "The lifetime of the stream tokens")
+	serveCmd.Flags().String("grpc", "", "The address to also serve the gRPC API on, see proto/webhelper/v1/webhelper.proto, :9090 if given without a value")
+	serveCmd.Flags().Lookup("grpc").NoOptDefVal = ":9090"
+	serveCmd.Flags().String("metricsAddr", "", "The address to also serve /metrics on without authentication, e.g. an internal interface")
+	serveCmd.Flags().String("apiKeys", "", "The JSON file of the accepted API keys")
+	serveCmd.Flags().String("jwks", "", "The JWK set file of the keys verifying the RS and HS JWTs by kid")
+	serveCmd.Flags().String("jwtSecret", "", "The secret verifying the HS JWTs without a kid")
+	serveCmd.Flags().String("jwtIssuer", "", "The required iss claim of the JWTs")
+	serveCmd.Flags().String("jwtAudience", "", "The required aud claim of the JWTs")
+	serveCmd.Flags().Float64("rateLimit", 0, "The requests per second of every caller, 0 for no limit")
+	serveCmd.Flags().Int("burst", 0, "The requests of every caller allowed at once above the rate limit")
+	serveCmd.Flags().Int("dailyQuota", 0, "The requests per UTC day of every caller, 0 for no limit")
+	serveCmd.Flags().Duration("shutdownTimeout", 10*time.Second, "How long to wait for the requests in flight on SIGINT or SIGTERM")
+	serveCmd.Flags().String("quotaFile", "", "The file keeping the daily request counts across restarts, empty keeps them in memory")
+}
+
+// newAuthenticator returns the authenticator of the auth flags, nil if none is set.
+func newAuthenticator(cmd *cobra.Command) (*internal.Authenticator, error) {
+	keysPath, _ := cmd.Flags().GetString("apiKeys")
+	jwksPath, _ := cmd.Flags().GetString("jwks")
+	jwtSecret, _ := cmd.Flags().GetString("jwtSecret")
+	if keysPath == "" && jwksPath == "" && jwtSecret == "" {
+		return nil, nil
+	}
+
+	var keys []internal.APIKey
+	if keysPath != "" {
+		var err error
+		if keys, err = internal.LoadAPIKeys(keysPath); err != nil {
+			return nil, err
+		}
+	}
+	auth := internal.NewAuthenticator(keys)
+
+	if jwksPath != "" || jwtSecret != "" {
+		verifier := &internal.JWTVerifier{}
+		if jwtSecret != "" {
+			verifier.Secret = []byte(jwtSecret)
+		}
+		if jwksPath != "" {
+			if err := verifier.LoadJWKS(jwksPath); err != nil {
+				return nil, err
+			}
+		}
+		verifier.Issuer, _ = cmd.Flags().GetString("jwtIssuer")
+		verifier.Audience, _ = cmd.Flags().GetString("jwtAudience")
+		auth.JWT = verifier
+	}
+
+	auth.Limits.RatePerSecond, _ = cmd.Flags().GetFloat64("rateLimit")
+	auth.Limits.Burst, _ = cmd.Flags().GetInt("burst")
+	auth.Limits.DailyQuota, _ = cmd.Flags().GetInt("dailyQuota")
+	if quotaFile, _ := cmd.Flags().GetString("quotaFile"); quotaFile != "" {
+		auth.Quotas = &internal.DiskQuotaStore{Path: quotaFile}
+	}
+
+	return auth, nil
+}
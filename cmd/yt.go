@@ -4,34 +4,102 @@
 package cmd
 
 import (
-	"encoding/json"
+	"fmt"
+	"time"
+
 	"github.com/spf13/cobra"
 	"web-helper/internal"
 )
 
 // ytCmd represents the yt command
 var ytCmd = &cobra.Command{
-	Use:   "yt",
+	Use:   "yt [videoId...]",
 	Short: "Get the YT video details",
-	Long:  `Request the YT video details from the YT API and return the details in JSON format.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		videoId, _ := cmd.Flags().GetString("videoId")
-		if videoId == "" {
-			cmd.Help()
-			return
+	Long: `Request the YT video details from the YT API and return the details in JSON format.
+
+Several video IDs can be passed as arguments to get the details of all of them at once. The command fails when
+any of them can't be resolved, including unplayable videos with --view descriptor and drift with --strict.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		videoIds := args
+		if videoId, _ := cmd.Flags().GetString("videoId"); videoId != "" {
+			videoIds = append([]string{videoId}, videoIds...)
+		}
+		if len(videoIds) == 0 {
+			return cmd.Help()
+		}
+
+		view, _ := cmd.Flags().GetString("view")
+		output, _ := cmd.Flags().GetString("output")
+		if output == "descriptor" {
+			// Kept as a shorthand for --view descriptor --output json.
+			view, output = "descriptor", "json"
+		}
+		if view != "raw" && view != "descriptor" {
+			return fmt.Errorf("unknown view: %s", view)
 		}
 
-		response, err := internal.GetPlayerResponse(videoId)
+		fields, _ := cmd.Flags().GetString("fields")
+		templateText, _ := cmd.Flags().GetString("template")
+		printer, err := newPrinter(output, fields, templateText)
 		if err != nil {
-			return
+			return err
 		}
 
-		serializedResponse, err := json.Marshal(response)
+		selectSpec, _ := cmd.Flags().GetString("select")
+		selector, err := internal.ParseSelector(selectSpec)
 		if err != nil {
-			return
+			return err
 		}
 
-		cmd.Println(string(serializedResponse))
+		// The arguments are valid, a failed lookup shouldn't print the usage.
+		cmd.SilenceUsage = true
+
+		ctx := cmd.Context()
+		if strict, _ := cmd.Flags().GetBool("strict"); strict {
+			ctx = internal.ContextWithStrict(ctx)
+		}
+
+		// A failed lookup doesn't stop the others, the command fails once they are printed.
+		var results []interface{}
+		failed := 0
+		for _, videoId := range videoIds {
+			response, err := internal.DefaultClient.GetPlayerResponse(ctx, videoId)
+			if err != nil {
+				cmd.PrintErrf("%s: %v\n", videoId, err)
+				failed++
+				continue
+			}
+
+			var result interface{} = response
+			if view == "descriptor" {
+				if err := response.PlayabilityStatus.Err(); err != nil {
+					cmd.PrintErrf("%s: %v\n", videoId, err)
+					failed++
+					continue
+				}
+				result = internal.NewDescriptor(videoId, response, selector, time.Now())
+			} else if selectSpec != "" {
+				selection, err := selector.Select(response.StreamingData)
+				if err != nil {
+					cmd.PrintErrf("%s: %v\n", videoId, err)
+					failed++
+					continue
+				}
+				result = selection
+			}
+
+			results = append(results, result)
+		}
+
+		if len(results) > 0 {
+			if err := printer.print(cmd.OutOrStdout(), results, len(videoIds) > 1); err != nil {
+				return err
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("failed to get %d of %d videos", failed, len(videoIds))
+		}
+		return nil
 	},
 }
 
@@ -39,4 +107,10 @@ func init() {
 	rootCmd.AddCommand(ytCmd)
 
 	ytCmd.Flags().StringP("videoId", "v", "", "The video ID")
+	ytCmd.Flags().StringP("select", "s", "", "Select streams instead of printing the full response, e.g. audio=spatial,maxHeight=1080,hdr=false,lang=de (audio: mono, stereo, multichannel, spatial)")
+	ytCmd.Flags().String("view", "raw", "The view of the details: raw player response or the compact media descriptor (raw, descriptor)")
+	ytCmd.Flags().StringP("output", "o", "json", "The output format (json, json-pretty, yaml, csv, table, template)")
+	ytCmd.Flags().StringP("fields", "f", "", "Comma-separated JSON field paths to output, e.g. videoDetails.title,streamingData.formats.0.url")
+	ytCmd.Flags().StringP("template", "t", "", "The Go template for --output template, e.g. '{{.VideoDetails.Title}}'")
+	ytCmd.Flags().Bool("strict", false, "Fail listing the unknown (+) and missing (-) fields when the player response drifted from the model")
 }
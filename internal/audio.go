@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"strconv"
+	"strings"
+)
+
+// AudioLayout describes the channel arrangement of an audio stream.
+type AudioLayout string
+
+const (
+	AudioLayoutNone         AudioLayout = ""
+	AudioLayoutMono         AudioLayout = "mono"
+	AudioLayoutStereo       AudioLayout = "stereo"
+	AudioLayoutMultichannel AudioLayout = "multichannel"
+	AudioLayoutSpatial      AudioLayout = "spatial"
+)
+
+// multichannelItags are the audio itags YT uses for 5.1 surround streams.
+var multichannelItags = map[int]bool{
+	256: true, // AAC HE v1 5.1
+	258: true, // AAC LC 5.1
+	325: true, // DTS Express 5.1
+	327: true, // AAC 5.1
+	328: true, // E-AC-3 5.1
+	380: true, // AC-3 5.1
+}
+
+// spatialItags are the audio itags YT uses for ambisonic streams.
+var spatialItags = map[int]bool{
+	338: true, // Opus ambisonic
+}
+
+// spatialAudioTypes are the spatial audio types YT reports for ambisonic streams. Any other
+// type, such as SPATIAL_AUDIO_TYPE_NONE, leaves the layout to the channel count.
+var spatialAudioTypes = map[string]bool{
+	"SPATIAL_AUDIO_TYPE_AMBISONICS_5_1":        true,
+	"SPATIAL_AUDIO_TYPE_AMBISONICS_QUAD":       true,
+	"SPATIAL_AUDIO_TYPE_FOA_WITH_NON_DIEGETIC": true,
+}
+
+// ParseAudioLayout converts a layout name as accepted on the command line to an AudioLayout.
+func ParseAudioLayout(value string) (AudioLayout, bool) {
+	switch layout := AudioLayout(strings.ToLower(value)); layout {
+	case AudioLayoutMono, AudioLayoutStereo, AudioLayoutMultichannel, AudioLayoutSpatial:
+		return layout, true
+	}
+	return AudioLayoutNone, false
+}
+
+// fallbacks returns the layout followed by the layouts to try when it is not available,
+// stepping down towards stereo and finally mono.
+func (l AudioLayout) fallbacks() []AudioLayout {
+	layouts := []AudioLayout{AudioLayoutSpatial, AudioLayoutMultichannel, AudioLayoutStereo, AudioLayoutMono}
+	for i, layout := range layouts {
+		if layout == l {
+			return layouts[i:]
+		}
+	}
+	return layouts[2:]
+}
+
+func audioLayout(itag int, channels int, spatialAudioType string) AudioLayout {
+	switch {
+	case spatialAudioTypes[spatialAudioType] || spatialItags[itag]:
+		return AudioLayoutSpatial
+	case channels > 2 || multichannelItags[itag]:
+		return AudioLayoutMultichannel
+	case channels == 1:
+		return AudioLayoutMono
+	}
+	return AudioLayoutStereo
+}
+
+func parseSampleRate(value string) int {
+	sampleRate, err := strconv.Atoi(value)
+	if err != nil {
+		return 0
+	}
+	return sampleRate
+}
+
+// HasAudio reports whether the muxed format carries an audio track.
+func (f Format) HasAudio() bool {
+	return f.AudioChannels > 0 || f.AudioQuality != ""
+}
+
+// AudioLayout returns the channel layout of the muxed format audio track.
+func (f Format) AudioLayout() AudioLayout {
+	if !f.HasAudio() {
+		return AudioLayoutNone
+	}
+	return audioLayout(f.Itag, f.AudioChannels, f.SpatialAudioType)
+}
+
+// SampleRate returns the audio sample rate in Hz, or 0 if unknown.
+func (f Format) SampleRate() int {
+	return parseSampleRate(f.AudioSampleRate)
+}
+
+// IsAudio reports whether the adaptive format is an audio-only stream.
+func (f AdaptiveFormat) IsAudio() bool {
+	return strings.HasPrefix(f.MimeType, "audio/")
+}
+
+// IsVideo reports whether the adaptive format is a video-only stream.
+func (f AdaptiveFormat) IsVideo() bool {
+	return strings.HasPrefix(f.MimeType, "video/")
+}
+
+// AudioLayout returns the channel layout of an audio-only adaptive format.
+func (f AdaptiveFormat) AudioLayout() AudioLayout {
+	if !f.IsAudio() {
+		return AudioLayoutNone
+	}
+	return audioLayout(f.Itag, f.AudioChannels, f.SpatialAudioType)
+}
+
+// SampleRate returns the audio sample rate in Hz, or 0 if unknown.
+func (f AdaptiveFormat) SampleRate() int {
+	return parseSampleRate(f.AudioSampleRate)
+}
+
+// Language returns the language code of the audio track, e.g. "en-US" for track "en-US.4".
+func (t AudioTrack) Language() string {
+	language, _, _ := strings.Cut(t.Id, ".")
+	return language
+}
+
+// Audio track kinds, as YT encodes them in the suffix of the track id, e.g. "en.4". Unlike
+// the display name, the suffix doesn't depend on the language of the client.
+const (
+	audioTrackDescriptive = "2"
+	audioTrackOriginal    = "4"
+)
+
+// kind returns the kind suffix of the track id.
+func (t AudioTrack) kind() string {
+	_, kind, _ := strings.Cut(t.Id, ".")
+	return kind
+}
+
+// IsOriginal reports whether the track is the original audio rather than a dub.
+func (t AudioTrack) IsOriginal() bool {
+	return t.kind() == audioTrackOriginal
+}
+
+// IsDescriptive reports whether the track is an audio description track.
+func (t AudioTrack) IsDescriptive() bool {
+	return t.kind() == audioTrackDescriptive
+}
+
+// MatchesLanguage reports whether the track is in the language, comparing the base
+// language when either side has no region, so "de" matches "de-DE".
+func (t AudioTrack) MatchesLanguage(language string) bool {
+	trackLanguage := strings.ToLower(t.Language())
+	language = strings.ToLower(language)
+	if trackLanguage == language {
+		return true
+	}
+
+	trackBase, trackRegion, _ := strings.Cut(trackLanguage, "-")
+	base, region, _ := strings.Cut(language, "-")
+	return trackBase == base && (trackRegion == "" || region == "")
+}
@@ -0,0 +1,270 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limits are the request limits of a caller, zero values disable the limit.
+type Limits struct {
+	// RatePerSecond is the sustained request rate.
+	RatePerSecond float64 `json:"ratePerSecond,omitempty"`
+	// Burst is the number of requests allowed at once above the rate.
+	Burst int `json:"burst,omitempty"`
+	// DailyQuota is the number of requests per UTC day.
+	DailyQuota int `json:"dailyQuota,omitempty"`
+}
+
+// APIKey is an API key of a caller with its own limits.
+type APIKey struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	// Limits override the default limits of the Authenticator if set.
+	Limits *Limits `json:"limits,omitempty"`
+}
+
+// LoadAPIKeys reads the API keys from a JSON array file.
+func LoadAPIKeys(path string) ([]APIKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []APIKey
+	if err := json.Unmarshal(content, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse API keys %s: %v", path, err)
+	}
+	for _, key := range keys {
+		if key.Key == "" || key.Name == "" {
+			return nil, fmt.Errorf("API key without key or name in %s", path)
+		}
+	}
+	return keys, nil
+}
+
+// LimitError is returned when the caller exceeded its rate limit or daily quota.
+type LimitError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *LimitError) Error() string {
+	return e.Message
+}
+
+// Authenticator authenticates the callers of the server by API key or JWT, sent as
+// Authorization: Bearer <key or JWT> or X-API-Key, and enforces their limits.
+type Authenticator struct {
+	// JWT verifies the JWT callers, identified by their subject, nil accepts API keys only.
+	JWT *JWTVerifier
+	// Limits apply to the JWT callers and the API keys without limits of their own.
+	Limits Limits
+	// Quotas counts the daily requests, nil keeps the counts in memory.
+	Quotas QuotaStore
+
+	keys        []hashedAPIKey
+	mu          sync.Mutex
+	buckets     map[string]*rateBucket
+	lastEvicted time.Time
+}
+
+// hashedAPIKey is an API key with the SHA-256 digest of the key it is compared by.
+type hashedAPIKey struct {
+	digest [sha256.Size]byte
+	APIKey
+}
+
+// NewAuthenticator returns an authenticator accepting the API keys.
+func NewAuthenticator(keys []APIKey) *Authenticator {
+	authenticator := &Authenticator{buckets: map[string]*rateBucket{}}
+	for _, key := range keys {
+		authenticator.keys = append(authenticator.keys, hashedAPIKey{digest: sha256.Sum256([]byte(key.Key)), APIKey: key})
+	}
+	return authenticator
+}
+
+// findKey returns the API key of the credential. The digests are compared in constant
+// time, and all of them, so the time taken doesn't tell how close a guess was.
+func (a *Authenticator) findKey(credential string) (APIKey, bool) {
+	digest := sha256.Sum256([]byte(credential))
+	var found *hashedAPIKey
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare(digest[:], a.keys[i].digest[:]) == 1 {
+			found = &a.keys[i]
+		}
+	}
+	if found == nil {
+		return APIKey{}, false
+	}
+	return found.APIKey, true
+}
+
+var errUnauthenticated = errors.New("missing credentials, send an API key or a JWT as Authorization: Bearer")
+
+// authenticate returns the caller of the request headers and its limits.
+func (a *Authenticator) authenticate(header http.Header, now time.Time) (string, Limits, error) {
+	credential := header.Get("X-API-Key")
+	if authorization := header.Get("Authorization"); credential == "" && authorization != "" {
+		scheme, value, _ := strings.Cut(authorization, " ")
+		if !strings.EqualFold(scheme, "Bearer") {
+			return "", Limits{}, errors.New("unsupported authorization scheme: " + scheme)
+		}
+		credential = strings.TrimSpace(value)
+	}
+	if credential == "" {
+		credential = websocketCredential(header)
+	}
+	if credential == "" {
+		return "", Limits{}, errUnauthenticated
+	}
+
+	if key, found := a.findKey(credential); found {
+		limits := a.Limits
+		if key.Limits != nil {
+			limits = *key.Limits
+		}
+		return "key:" + key.Name, limits, nil
+	}
+
+	if a.JWT == nil || strings.Count(credential, ".") != 2 {
+		return "", Limits{}, errors.New("invalid API key")
+	}
+	claims, err := a.JWT.Verify(credential, now)
+	if err != nil {
+		return "", Limits{}, err
+	}
+	return "jwt:" + claims.Subject, a.Limits, nil
+}
+
+// websocketCredential returns the credential offered as the bearer.<credential>
+// subprotocol of a WebSocket handshake, by the browsers.
+func websocketCredential(header http.Header) string {
+	for _, value := range header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(value, ",") {
+			if credential, found := strings.CutPrefix(strings.TrimSpace(protocol), websocketCredentialPrefix); found {
+				return credential
+			}
+		}
+	}
+	return ""
+}
+
+// admit counts the request against the limits of the caller.
+func (a *Authenticator) admit(caller string, limits Limits, now time.Time) error {
+	if limits.RatePerSecond > 0 {
+		a.mu.Lock()
+		bucket, found := a.buckets[caller]
+		if !found {
+			bucket = &rateBucket{tokens: float64(limits.burst()), updated: now}
+			a.buckets[caller] = bucket
+		}
+		wait := bucket.take(limits, now)
+		a.evictFull(now)
+		a.mu.Unlock()
+
+		if wait > 0 {
+			return &LimitError{Message: "rate limit exceeded", RetryAfter: wait}
+		}
+	}
+
+	if limits.DailyQuota > 0 {
+		quotas := a.Quotas
+		if quotas == nil {
+			a.mu.Lock()
+			if a.Quotas == nil {
+				a.Quotas = &MemoryQuotaStore{}
+			}
+			quotas = a.Quotas
+			a.mu.Unlock()
+		}
+
+		day, untilTomorrow := quotaDay(now)
+		count, err := quotas.Increment(caller, day)
+		if err != nil {
+			return err
+		}
+		if count > limits.DailyQuota {
+			return &LimitError{Message: "daily quota exceeded", RetryAfter: untilTomorrow}
+		}
+	}
+
+	return nil
+}
+
+// evictFull drops the buckets refilled to their burst since their last request, at most
+// once a minute. They would be created full again, so one-off callers don't pile up.
+func (a *Authenticator) evictFull(now time.Time) {
+	if now.Sub(a.lastEvicted) < time.Minute {
+		return
+	}
+	a.lastEvicted = now
+
+	for caller, bucket := range a.buckets {
+		if !now.Before(bucket.full) {
+			delete(a.buckets, caller)
+		}
+	}
+}
+
+func (l Limits) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return int(math.Max(1, math.Ceil(l.RatePerSecond)))
+}
+
+// rateBucket is a token bucket refilled at the rate of the limits.
+type rateBucket struct {
+	tokens  float64
+	updated time.Time
+	// full is when the bucket is refilled to the burst, burst/rate after a request at most.
+	full time.Time
+}
+
+// take takes a token for a request, or returns how long to wait for one.
+func (b *rateBucket) take(limits Limits, now time.Time) time.Duration {
+	burst := float64(limits.burst())
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.updated).Seconds()*limits.RatePerSecond)
+	b.updated = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / limits.RatePerSecond * float64(time.Second))
+	}
+	b.tokens--
+	b.full = now.Add(time.Duration((burst - b.tokens) / limits.RatePerSecond * float64(time.Second)))
+	return 0
+}
+
+// authorize authenticates the request and enforces the limits of the caller, writing
+// 401 or 429 and returning false if the request must not be served.
+func (a *Authenticator) authorize(w http.ResponseWriter, r *http.Request) bool {
+	now := time.Now()
+	caller, limits, err := a.authenticate(r.Header, now)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="web-helper"`)
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return false
+	}
+
+	if err := a.admit(caller, limits, now); err != nil {
+		var limitErr *LimitError
+		if errors.As(err, &limitErr) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(limitErr.RetryAfter.Seconds()))))
+			writeError(w, http.StatusTooManyRequests, err.Error())
+			return false
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAuthenticateAPIKeys(t *testing.T) {
+	keyLimits := &Limits{RatePerSecond: 10}
+	auth := NewAuthenticator([]APIKey{
+		{Key: "key-one", Name: "one"},
+		{Key: "key-two", Name: "two", Limits: keyLimits},
+	})
+	auth.Limits = Limits{RatePerSecond: 1}
+
+	tests := []struct {
+		name       string
+		header     http.Header
+		wantCaller string
+		wantLimits Limits
+		wantErr    bool
+	}{
+		{name: "X-API-Key", header: http.Header{"X-Api-Key": {"key-one"}}, wantCaller: "key:one", wantLimits: auth.Limits},
+		{name: "bearer", header: http.Header{"Authorization": {"Bearer key-two"}}, wantCaller: "key:two", wantLimits: *keyLimits},
+		{name: "bearer lowercase", header: http.Header{"Authorization": {"bearer  key-one "}}, wantCaller: "key:one", wantLimits: auth.Limits},
+		{name: "X-API-Key first", header: http.Header{"X-Api-Key": {"key-one"}, "Authorization": {"Bearer key-two"}}, wantCaller: "key:one", wantLimits: auth.Limits},
+		{name: "unknown key", header: http.Header{"X-Api-Key": {"key-three"}}, wantErr: true},
+		{name: "key prefix", header: http.Header{"X-Api-Key": {"key-on"}}, wantErr: true},
+		{name: "basic auth", header: http.Header{"Authorization": {"Basic a2V5LW9uZQ=="}}, wantErr: true},
+		{name: "no credentials", header: http.Header{}, wantErr: true},
+		{name: "JWT without verifier", header: http.Header{"Authorization": {"Bearer a.b.c"}}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			caller, limits, err := auth.authenticate(test.header, time.Now())
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("authenticated %s", caller)
+				}
+				return
+			}
+			if err != nil || caller != test.wantCaller || limits != test.wantLimits {
+				t.Errorf("authenticate = %s, %+v, %v, want %s, %+v", caller, limits, err, test.wantCaller, test.wantLimits)
+			}
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	auth := NewAuthenticator(nil)
+	limits := Limits{RatePerSecond: 2, Burst: 3}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// The burst is served at once, the next request has to wait for a token.
+	for i := 0; i < 3; i++ {
+		if err := auth.admit("jwt:user", limits, now); err != nil {
+			t.Fatalf("request %d of the burst refused: %v", i+1, err)
+		}
+	}
+	var limitErr *LimitError
+	if err := auth.admit("jwt:user", limits, now); !errors.As(err, &limitErr) || limitErr.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("admit = %v, want a rate limit error retrying after 500ms", err)
+	}
+
+	// Other callers have their own buckets.
+	if err := auth.admit("jwt:other", limits, now); err != nil {
+		t.Fatalf("other caller refused: %v", err)
+	}
+
+	// Refilled at the rate: one token after 500ms, the burst after 1.5s.
+	now = now.Add(500 * time.Millisecond)
+	if err := auth.admit("jwt:user", limits, now); err != nil {
+		t.Fatalf("refilled token refused: %v", err)
+	}
+	if err := auth.admit("jwt:user", limits, now); err == nil {
+		t.Fatal("admitted above the rate")
+	}
+	now = now.Add(10 * time.Second)
+	for i := 0; i < 3; i++ {
+		if err := auth.admit("jwt:user", limits, now); err != nil {
+			t.Fatalf("request %d of the refilled burst refused: %v", i+1, err)
+		}
+	}
+	if err := auth.admit("jwt:user", limits, now); err == nil {
+		t.Fatal("the bucket refilled above the burst")
+	}
+}
+
+func TestRateLimitDefaultBurst(t *testing.T) {
+	auth := NewAuthenticator(nil)
+	now := time.Now()
+
+	for _, test := range []struct {
+		limits Limits
+		burst  int
+	}{
+		{Limits{RatePerSecond: 0.5}, 1},
+		{Limits{RatePerSecond: 2.5}, 3},
+	} {
+		caller := fmt.Sprintf("key:%g", test.limits.RatePerSecond)
+		for i := 0; i < test.burst; i++ {
+			if err := auth.admit(caller, test.limits, now); err != nil {
+				t.Fatalf("rate %g: request %d refused: %v", test.limits.RatePerSecond, i+1, err)
+			}
+		}
+		if err := auth.admit(caller, test.limits, now); err == nil {
+			t.Errorf("rate %g: admitted above the burst of %d", test.limits.RatePerSecond, test.burst)
+		}
+	}
+}
+
+func TestRateBucketEviction(t *testing.T) {
+	auth := NewAuthenticator(nil)
+	limits := Limits{RatePerSecond: 1, Burst: 10}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// The first request sweeps, there is nothing to evict yet.
+	if err := auth.admit("jwt:one-off", limits, start); err != nil {
+		t.Fatal(err)
+	}
+	// A busy caller, empty until well after the next sweep.
+	for i := 0; i < 10; i++ {
+		if err := auth.admit("jwt:busy", limits, start.Add(time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The one-off caller is refilled a second after its request, the busy one 10s after.
+	now := start.Add(time.Minute + time.Second/2)
+	if err := auth.admit("jwt:new", limits, now); err != nil {
+		t.Fatal(err)
+	}
+	auth.mu.Lock()
+	_, oneOff := auth.buckets["jwt:one-off"]
+	_, busy := auth.buckets["jwt:busy"]
+	auth.mu.Unlock()
+	if oneOff || busy {
+		t.Errorf("kept the refilled buckets: one-off %t, busy %t", oneOff, busy)
+	}
+
+	// A bucket still refilling at the next sweep is kept.
+	for i := 0; i < 10; i++ {
+		if err := auth.admit("jwt:busy", limits, now.Add(55*time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := auth.admit("jwt:other", limits, now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	auth.mu.Lock()
+	_, busy = auth.buckets["jwt:busy"]
+	_, newCaller := auth.buckets["jwt:new"]
+	auth.mu.Unlock()
+	if !busy || newCaller {
+		t.Errorf("kept the buckets: busy %t, want true, new %t, want false", busy, newCaller)
+	}
+}
@@ -0,0 +1,347 @@
+package internal
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrCacheMiss = errors.New("cache miss")
+
+// DefaultMaxMemoryCacheEntries bounds the memory cache of NewCache.
+const DefaultMaxMemoryCacheEntries = 10000
+
+// diskCacheCollectInterval is how often the disk cache removes its expired files.
+const diskCacheCollectInterval = 10 * time.Minute
+
+// Cache stores the player responses and the artifacts derived from them, such as
+// manifests and captions, so the replicas of the helper share their lookups. Player
+// responses are only shared by the replicas with the same egress, see Client.Egress,
+// artifacts by their URL.
+type Cache interface {
+	// Get returns the value of the key, ErrCacheMiss if absent or expired.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores the value of the key for the ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the key.
+	Delete(ctx context.Context, key string) error
+}
+
+// NewCache returns the cache of the spec: memory, disk (in dir) or a redis://[:password@]host:port[/db] URL.
+func NewCache(spec string, dir string) (Cache, error) {
+	switch {
+	case spec == "memory":
+		return &MemoryCache{MaxEntries: DefaultMaxMemoryCacheEntries}, nil
+	case spec == "disk":
+		return &DiskCache{Dir: dir}, nil
+	case strings.HasPrefix(spec, "redis://"):
+		return NewRedisCache(spec)
+	}
+	return nil, fmt.Errorf("unknown cache: %s", spec)
+}
+
+type memoryCacheEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryCache keeps the values in memory, shared within the process only.
+type MemoryCache struct {
+	// MaxEntries bounds the number of entries, the least recently used are evicted
+	// first. 0 means no bound.
+	MaxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// recent holds the entries, the most recently used first.
+	recent      list.List
+	lastEvicted time.Time
+}
+
+func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, found := c.entries[key]
+	if !found {
+		return nil, ErrCacheMiss
+	}
+	entry := element.Value.(*memoryCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.remove(element)
+		return nil, ErrCacheMiss
+	}
+	c.recent.MoveToFront(element)
+	return entry.value, nil
+}
+
+func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.entries == nil {
+		c.entries = map[string]*list.Element{}
+	}
+	// Expired entries are dropped at most once a minute.
+	if now.Sub(c.lastEvicted) > time.Minute {
+		c.lastEvicted = now
+		for _, element := range c.entries {
+			if now.After(element.Value.(*memoryCacheEntry).expiresAt) {
+				c.remove(element)
+			}
+		}
+	}
+
+	if element, found := c.entries[key]; found {
+		element.Value = &memoryCacheEntry{key: key, value: value, expiresAt: now.Add(ttl)}
+		c.recent.MoveToFront(element)
+		return nil
+	}
+	c.entries[key] = c.recent.PushFront(&memoryCacheEntry{key: key, value: value, expiresAt: now.Add(ttl)})
+	for c.MaxEntries > 0 && len(c.entries) > c.MaxEntries {
+		c.remove(c.recent.Back())
+	}
+	return nil
+}
+
+func (c *MemoryCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, found := c.entries[key]; found {
+		c.remove(element)
+	}
+	return nil
+}
+
+func (c *MemoryCache) remove(element *list.Element) {
+	c.recent.Remove(element)
+	delete(c.entries, element.Value.(*memoryCacheEntry).key)
+}
+
+// DiskCache keeps every value in a file of the directory, named by the hash of the key.
+// The first line of a file is its expiry in unix seconds. Replicas sharing a volume share
+// the cache. Expired files are removed when read, and by a pass over the directory at
+// most every 10 minutes.
+type DiskCache struct {
+	Dir string
+
+	mu            sync.Mutex
+	lastCollected time.Time
+}
+
+func (c *DiskCache) path(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(hash[:16]))
+}
+
+func (c *DiskCache) Get(ctx context.Context, key string) ([]byte, error) {
+	content, err := os.ReadFile(c.path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrCacheMiss
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	header, value, found := bytes.Cut(content, []byte("\n"))
+	expiresAt, err := strconv.ParseInt(string(header), 10, 64)
+	if !found || err != nil || time.Now().Unix() > expiresAt {
+		_ = os.Remove(c.path(key))
+		return nil, ErrCacheMiss
+	}
+	return value, nil
+}
+
+func (c *DiskCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	collect := now.Sub(c.lastCollected) > diskCacheCollectInterval
+	if collect {
+		c.lastCollected = now
+	}
+	c.mu.Unlock()
+	if collect {
+		go c.collect(now)
+	}
+
+	content := make([]byte, 0, len(value)+21)
+	content = strconv.AppendInt(content, now.Add(ttl).Unix(), 10)
+	content = append(content, '\n')
+	content = append(content, value...)
+
+	// Write then rename so readers never see a partial file.
+	temp, err := os.CreateTemp(c.Dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := temp.Write(content); err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+	return os.Rename(temp.Name(), c.path(key))
+}
+
+func (c *DiskCache) Delete(ctx context.Context, key string) error {
+	err := os.Remove(c.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// collect removes the files expired at now, and the temporary files a crashed writer
+// left behind an hour ago.
+func (c *DiskCache) collect(now time.Time) {
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		path := filepath.Join(c.Dir, entry.Name())
+		if strings.HasPrefix(entry.Name(), ".tmp-") {
+			if info, err := entry.Info(); err == nil && now.Sub(info.ModTime()) > time.Hour {
+				_ = os.Remove(path)
+			}
+			continue
+		}
+		if expired, err := diskCacheFileExpired(path, now); err == nil && expired {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+// diskCacheFileExpired reads the expiry line of the file, a file without one is expired.
+func diskCacheFileExpired(path string, now time.Time) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	header := make([]byte, 21)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+	line, _, found := bytes.Cut(header[:n], []byte("\n"))
+	expiresAt, err := strconv.ParseInt(string(line), 10, 64)
+	return !found || err != nil || now.Unix() > expiresAt, nil
+}
+
+// playerCacheKey is the cache key of the player response of the video as seen by the
+// client, the response depends on the client profile and locale. The media URLs are bound
+// to the IP the video was resolved from, so the key also holds the egress: the proxy of
+// the video or the Egress of the client. It returns false while the video has no proxy
+// yet, as its next resolution may go through any proxy of the pool.
+func (c *Client) playerCacheKey(videoID string) (string, bool) {
+	egress := c.Egress
+	if c.Proxies != nil {
+		if egress = c.Proxies.videoProxy(videoID, time.Now()); egress == "" {
+			return "", false
+		}
+	}
+	return strings.Join([]string{"player", c.Profile.Name, c.Language, c.Region, egress, videoID}, ":"), true
+}
+
+// artifactCacheKey is the cache key of an artifact, such as a manifest or a caption
+// track, fetched from the URL.
+func artifactCacheKey(artifactURL string) string {
+	hash := sha256.Sum256([]byte(artifactURL))
+	return "artifact:" + hex.EncodeToString(hash[:16])
+}
+
+// encodeCachedPlayerResponse prefixes the cached player response body with the time it was
+// fetched, so the expiry of a cache hit is relative to the original request.
+func encodeCachedPlayerResponse(body []byte, fetchedAt time.Time) []byte {
+	value := strconv.AppendInt(make([]byte, 0, len(body)+21), fetchedAt.Unix(), 10)
+	value = append(value, '\n')
+	return append(value, body...)
+}
+
+func decodeCachedPlayerResponse(value []byte, now time.Time) (*PlayerResponse, error) {
+	header, body, found := bytes.Cut(value, []byte("\n"))
+	fetchedAt, err := strconv.ParseInt(string(header), 10, 64)
+	if !found || err != nil {
+		return nil, errors.New("invalid cached player response")
+	}
+
+	response, err := parsePlayerResponse(body)
+	if err != nil {
+		return nil, err
+	}
+
+	// The media URLs expire relative to the original request.
+	streamingData := &response.StreamingData
+	if expiresIn, err := strconv.Atoi(streamingData.ExpiresInSeconds); err == nil {
+		age := int(now.Unix() - fetchedAt)
+		streamingData.ExpiresInSeconds = strconv.Itoa(expiresIn - age)
+	}
+	return response, nil
+}
+
+// playerResponseTTL returns how long the player response can be cached: until its media
+// URLs expire, 0 if it must not be cached.
+func playerResponseTTL(response *PlayerResponse) time.Duration {
+	if response.PlayabilityStatus.Status != "OK" {
+		return 0
+	}
+	expiresIn, err := strconv.Atoi(response.StreamingData.ExpiresInSeconds)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(expiresIn)*time.Second - mediaURLExpiryMargin
+}
+
+// FetchArtifact fetches an artifact URL of the video, such as a manifest or a caption
+// track, through the cache. The artifact is cached for the ttl.
+func (c *Client) FetchArtifact(ctx context.Context, videoID string, artifactURL string, ttl time.Duration) ([]byte, error) {
+	if _, err := url.Parse(artifactURL); err != nil {
+		return nil, err
+	}
+
+	key := artifactCacheKey(artifactURL)
+	if c.Cache != nil {
+		value, err := c.Cache.Get(ctx, key)
+		if err == nil {
+			return value, nil
+		}
+		if !errors.Is(err, ErrCacheMiss) {
+			c.log(ctx, LevelWarn, "cache get failed", "key", key, "error", err)
+		}
+	}
+
+	body, err := c.get(ContextWithMedia(ctx, videoID), artifactURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Cache != nil && ttl > 0 {
+		if err := c.Cache.Set(ctx, key, body, ttl); err != nil {
+			c.log(ctx, LevelWarn, "cache set failed", "key", key, "error", err)
+		}
+	}
+	return body, nil
+}
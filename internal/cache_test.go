@@ -0,0 +1,287 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheLRU(t *testing.T) {
+	ctx := context.Background()
+	cache := &MemoryCache{MaxEntries: 2}
+
+	cache.Set(ctx, "a", []byte("1"), time.Hour)
+	cache.Set(ctx, "b", []byte("2"), time.Hour)
+	// a is used, so b is the least recently used when c comes in.
+	if _, err := cache.Get(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	cache.Set(ctx, "c", []byte("3"), time.Hour)
+
+	for key, want := range map[string]string{"a": "1", "b": "", "c": "3"} {
+		value, err := cache.Get(ctx, key)
+		if want == "" {
+			if !errors.Is(err, ErrCacheMiss) {
+				t.Errorf("Get(%s) = %s, %v, want a miss", key, value, err)
+			}
+			continue
+		}
+		if err != nil || string(value) != want {
+			t.Errorf("Get(%s) = %s, %v, want %s", key, value, err, want)
+		}
+	}
+
+	// Setting a key again replaces its value without evicting.
+	cache.Set(ctx, "a", []byte("4"), time.Hour)
+	if value, err := cache.Get(ctx, "a"); err != nil || string(value) != "4" {
+		t.Errorf("Get(a) = %s, %v, want 4", value, err)
+	}
+	if _, err := cache.Get(ctx, "c"); err != nil {
+		t.Errorf("Get(c) = %v after replacing a", err)
+	}
+}
+
+func TestMemoryCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	cache := &MemoryCache{}
+
+	cache.Set(ctx, "expired", []byte("1"), -time.Second)
+	cache.Set(ctx, "deleted", []byte("2"), time.Hour)
+	cache.Delete(ctx, "deleted")
+	for _, key := range []string{"expired", "deleted", "absent"} {
+		if _, err := cache.Get(ctx, key); !errors.Is(err, ErrCacheMiss) {
+			t.Errorf("Get(%s) = %v, want a miss", key, err)
+		}
+	}
+	if len(cache.entries) != 0 || cache.recent.Len() != 0 {
+		t.Errorf("kept %d entries, %d in the recent list", len(cache.entries), cache.recent.Len())
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	// Collected by the test, not in the background.
+	cache := &DiskCache{Dir: dir, lastCollected: time.Now()}
+
+	if err := cache.Set(ctx, "fresh", []byte("1\n2"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Set(ctx, "expired", []byte("3"), -time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := cache.Get(ctx, "fresh"); err != nil || string(value) != "1\n2" {
+		t.Errorf("Get(fresh) = %q, %v, want %q", value, err, "1\n2")
+	}
+
+	// Files left by an older version or a crashed writer.
+	if err := os.WriteFile(filepath.Join(dir, "invalid"), []byte("soon\nvalue"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for name, age := range map[string]time.Duration{".tmp-old": 2 * time.Hour, ".tmp-writing": 0} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("partial"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		modified := time.Now().Add(-age)
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cache.collect(time.Now())
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if want := []string{".tmp-writing", filepath.Base(cache.path("fresh"))}; len(names) != 2 || names[0] != want[0] || names[1] != want[1] {
+		t.Errorf("kept %v, want %v", names, want)
+	}
+}
+
+// fakeRedisServer serves a FakeRedis, closing the accepted connections on closeConns.
+type fakeRedisServer struct {
+	net.Listener
+
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func newFakeRedisServer(t *testing.T, password string) *fakeRedisServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeRedisServer{Listener: listener}
+	go (&FakeRedis{Password: password}).Serve(server)
+	t.Cleanup(func() {
+		server.Close()
+		server.closeConns()
+	})
+	return server
+}
+
+func (s *fakeRedisServer) Accept() (net.Conn, error) {
+	conn, err := s.Listener.Accept()
+	if err == nil {
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.mu.Unlock()
+	}
+	return conn, err
+}
+
+func (s *fakeRedisServer) closeConns() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+func TestRedisCache(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRedisServer(t, "secret")
+	cache, err := NewRedisCache("redis://:secret@" + server.Addr().String() + "/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cache.Set(ctx, "key", []byte("a\r\nvalue"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := cache.Get(ctx, "key"); err != nil || string(value) != "a\r\nvalue" {
+		t.Errorf("Get = %q, %v, want %q", value, err, "a\r\nvalue")
+	}
+	if _, err := cache.Get(ctx, "absent"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Get(absent) = %v, want a miss", err)
+	}
+
+	if err := cache.Delete(ctx, "key"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.Get(ctx, "key"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Get after Delete = %v, want a miss", err)
+	}
+
+	// The TTL is sent in milliseconds.
+	if err := cache.Set(ctx, "short", []byte("value"), 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.Get(ctx, "short"); err != nil {
+		t.Errorf("Get before the TTL = %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, err := cache.Get(ctx, "short"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Get after the TTL = %v, want a miss", err)
+	}
+
+	wrongPassword, err := NewRedisCache("redis://:guess@" + server.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var redisErr *RedisError
+	if _, err := wrongPassword.Get(ctx, "key"); !errors.As(err, &redisErr) {
+		t.Errorf("Get with a wrong password = %v, want a RedisError", err)
+	}
+}
+
+func TestRedisCacheStaleConnections(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRedisServer(t, "")
+	cache, err := NewRedisCache("redis://" + server.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two idle connections, both closed by the server.
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := cache.Set(ctx, "key", []byte("value"), time.Hour); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	server.closeConns()
+
+	if value, err := cache.Get(ctx, "key"); err != nil || string(value) != "value" {
+		t.Errorf("Get over stale connections = %q, %v, want value", value, err)
+	}
+
+	// A new connection is not tried again.
+	server.Close()
+	server.closeConns()
+	if _, err := cache.Get(ctx, "key"); err == nil {
+		t.Error("Get succeeded without a server")
+	}
+}
+
+func TestServerManifest(t *testing.T) {
+	var fetches int32
+	manifests := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		io.WriteString(w, "#EXTM3U "+r.URL.Path)
+	}))
+	defer manifests.Close()
+
+	innertube := newInnertubeServer(t, `{
+		"responseContext": {},
+		"playabilityStatus": {"status": "OK"},
+		"streamingData": {"expiresInSeconds": "21540", "hlsManifestUrl": "`+manifests.URL+`/hls"}
+	}`)
+	client := NewClient()
+	client.BaseURL = innertube.URL
+	client.Cache = &MemoryCache{}
+	server := httptest.NewServer(NewServer(client))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		response, err := http.Get(server.URL + "/v1/manifest?videoId=dQw4w9WgXcQ&type=hls")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+		if response.StatusCode != http.StatusOK || string(body) != "#EXTM3U /hls" ||
+			response.Header.Get("Content-Type") != "application/vnd.apple.mpegurl" {
+			t.Fatalf("manifest %d: %d %s %q", i+1, response.StatusCode, response.Header.Get("Content-Type"), body)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("fetched the manifest %d times, want once through the cache", fetches)
+	}
+
+	for query, want := range map[string]int{
+		"videoId=dQw4w9WgXcQ&type=dash": http.StatusNotFound,
+		"videoId=dQw4w9WgXcQ&type=smil": http.StatusBadRequest,
+		"type=hls":                      http.StatusBadRequest,
+	} {
+		response, err := http.Get(server.URL + "/v1/manifest?" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		if response.StatusCode != want {
+			t.Errorf("%s: status %d, want %d", query, response.StatusCode, want)
+		}
+	}
+}
@@ -0,0 +1,379 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const DefaultBaseURL = "https://www.youtube.com/youtubei/v1"
+
+// ClientProfile is the innertube client the requests are made as.
+type ClientProfile struct {
+	Name              string
+	Version           string
+	AndroidSdkVersion int
+	UserAgent         string
+}
+
+// AndroidTestSuiteProfile returns direct stream URLs without signature ciphers.
+var AndroidTestSuiteProfile = ClientProfile{
+	Name:              "ANDROID_TESTSUITE",
+	Version:           "1.9",
+	AndroidSdkVersion: 30,
+	UserAgent:         "com.google.android.youtube/17.36.4 (Linux; U; Android 12; GB) gzip",
+}
+
+// AndroidProfile is the YT Android app.
+var AndroidProfile = ClientProfile{
+	Name:              "ANDROID",
+	Version:           "19.09.37",
+	AndroidSdkVersion: 30,
+	UserAgent:         "com.google.android.youtube/19.09.37 (Linux; U; Android 11) gzip",
+}
+
+// IOSProfile is the YT iOS app.
+var IOSProfile = ClientProfile{
+	Name:      "IOS",
+	Version:   "19.09.3",
+	UserAgent: "com.google.ios.youtube/19.09.3 (iPhone14,3; U; CPU iOS 15_6 like Mac OS X)",
+}
+
+// ClientProfiles are the known profiles by name.
+var ClientProfiles = map[string]ClientProfile{
+	AndroidTestSuiteProfile.Name: AndroidTestSuiteProfile,
+	AndroidProfile.Name:          AndroidProfile,
+	IOSProfile.Name:              IOSProfile,
+}
+
+// Logger is the logging interface of the Client, satisfied by *log.Logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Client requests the YT innertube API. The zero value is not usable, use NewClient.
+type Client struct {
+	// BaseURL is the innertube API root, override it to point the client to a test server.
+	BaseURL string
+	// HTTPClient sends the requests.
+	HTTPClient *http.Client
+	// Timeout limits each request on top of the context deadline, 0 means no limit.
+	Timeout time.Duration
+	// Headers are added to every request.
+	Headers http.Header
+	// Profile is the innertube client the requests are made as.
+	Profile ClientProfile
+	// Language is the interface language (hl) of the responses, e.g. en.
+	Language string
+	// Region is the content region (gl) of the responses, e.g. US.
+	Region string
+	// UTCOffsetMinutes is the client time zone offset.
+	UTCOffsetMinutes int
+	// Logger receives request diagnostics, nil disables logging.
+	Logger Logger
+	// Retry is the retry policy of the innertube requests, all of which are idempotent.
+	Retry RetryPolicy
+	// Breaker stops sending requests while the upstream is overloaded, nil disables it.
+	Breaker *CircuitBreaker
+	// Strict fails with a SchemaDriftError when the player response drifted from the
+	// PlayerResponse model. ContextWithStrict does so for single calls.
+	Strict bool
+	// Sessions keeps the visitor data and the cookies across requests, nil sends none.
+	Sessions *SessionStore
+	// Cache shares the player responses between lookups and replicas, nil disables it.
+	Cache Cache
+	// Egress identifies the IP the requests leave from when not going through Proxies,
+	// e.g. a NAT gateway. Cached player responses are only shared by clients with the same
+	// egress, as their media URLs are bound to its IP. NewClient sets the host name.
+	Egress string
+	// Proxies is the pool the HTTPClient sends the requests through, if any. Cached player
+	// responses are then shared per proxy.
+	Proxies *ProxyPool
+	// Coalesce shares one upstream call between the concurrent GetPlayerResponse calls
+	// of the same video, which then return the same *PlayerResponse.
+	Coalesce bool
+
+	coalescer coalescer
+}
+
+// DefaultClient is used by GetPlayerResponse.
+var DefaultClient = NewClient()
+
+// NewClient returns a client with the default settings.
+func NewClient() *Client {
+	hostname, _ := os.Hostname()
+	return &Client{
+		BaseURL:    DefaultBaseURL,
+		HTTPClient: &http.Client{},
+		Timeout:    time.Second * 10,
+		Headers:    http.Header{},
+		Profile:    AndroidTestSuiteProfile,
+		Language:   "en",
+		Region:     "US",
+		Egress:     hostname,
+		Retry:      DefaultRetryPolicy(),
+		Breaker:    NewCircuitBreaker(5, 30*time.Second),
+		Sessions:   &SessionStore{session: Session{CreatedAt: time.Now()}},
+		Coalesce:   true,
+	}
+}
+
+// post sends the request body to the innertube endpoint and passes the response body
+// to read while the request context is still alive.
+func (c *Client) post(ctx context.Context, endpoint string, requestBody []byte, read func(body io.Reader) error) (err error) {
+	ctx, span := tracer.Start(ctx, "innertube "+endpoint, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("innertube.profile", c.Profile.Name)))
+	defer func() { endSpan(span, err) }()
+
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	url := c.BaseURL + "/" + endpoint
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(requestBody))
+	if err != nil {
+		return err
+	}
+
+	for key, values := range c.Headers {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if request.Header.Get("User-Agent") == "" {
+		request.Header.Set("User-Agent", c.Profile.UserAgent)
+	}
+	if c.Sessions != nil {
+		visitorData, cookies := c.Sessions.begin(time.Now())
+		if visitorData != "" {
+			request.Header.Set("X-Goog-Visitor-Id", visitorData)
+		}
+		for _, cookie := range cookies {
+			request.AddCookie(cookie)
+		}
+	}
+
+	start := time.Now()
+	response, err := c.HTTPClient.Do(request)
+	upstreamDuration.ObserveSince(start, endpoint, c.Profile.Name)
+	if err != nil {
+		upstreamRequests.Inc(endpoint, c.Profile.Name, "error")
+		c.log(ctx, LevelWarn, "innertube request failed", "url", url, "error", err)
+		return err
+	}
+	defer response.Body.Close()
+	upstreamRequests.Inc(endpoint, c.Profile.Name, strconv.Itoa(response.StatusCode))
+	span.SetAttributes(attribute.Int("http.status_code", response.StatusCode))
+
+	c.log(ctx, LevelDebug, "innertube request", "url", url, "status", response.StatusCode, "duration", time.Since(start))
+	c.updateSession(ctx, "", response.Cookies())
+
+	if response.StatusCode != http.StatusOK {
+		return newStatusError(response, time.Now())
+	}
+
+	if err := read(response.Body); err != nil {
+		return err
+	}
+
+	// Drain what the reader left so the connection can be reused.
+	_, _ = io.Copy(io.Discard, response.Body)
+	return nil
+}
+
+// readAll returns a post reader buffering the whole response body into target.
+func readAll(target *[]byte) func(body io.Reader) error {
+	return func(body io.Reader) (err error) {
+		*target, err = io.ReadAll(body)
+		return err
+	}
+}
+
+// GetPlayerResponseBody requests the raw player response body of the video. Unlike
+// GetPlayerResponse it doesn't keep the visitor data of the response, as it doesn't parse it.
+func (c *Client) GetPlayerResponseBody(ctx context.Context, videoID string) ([]byte, error) {
+	ctx = ContextWithVideo(ctx, videoID)
+	var responseBody []byte
+	if err := c.call(ctx, "player", c.newPlayerRequest(videoID), readAll(&responseBody)); err != nil {
+		return nil, err
+	}
+	return responseBody, nil
+}
+
+func (c *Client) newPlayerRequest(videoID string) PlayerRequest {
+	return PlayerRequest{
+		Context: c.NewContext(),
+		VideoId: videoID,
+	}
+}
+
+// GetPlayerResponse requests the player response of the video. The response must not be
+// modified, as it may be shared with concurrent callers.
+func (c *Client) GetPlayerResponse(ctx context.Context, videoID string) (playerResponse *PlayerResponse, err error) {
+	ctx, span := tracer.Start(ctx, "GetPlayerResponse", trace.WithAttributes(attribute.String("video.id", videoID)))
+	start := time.Now()
+	defer func() {
+		playerLookupDuration.ObserveSince(start)
+		if err != nil {
+			playerLookups.Inc("error")
+			c.log(ctx, LevelWarn, "player lookup failed", "videoId", videoID, "error", err)
+		} else {
+			playerLookups.Inc(playerResponse.PlayabilityStatus.Status)
+			span.SetAttributes(attribute.String("video.playability", playerResponse.PlayabilityStatus.Status))
+		}
+		endSpan(span, err)
+	}()
+
+	if !c.Coalesce {
+		return c.getPlayerResponse(ctx, videoID)
+	}
+	return c.coalescer.do(ctx, videoID, func(ctx context.Context) (*PlayerResponse, error) {
+		return c.getPlayerResponse(ctx, videoID)
+	})
+}
+
+// CoalesceStats returns the counts of the coalesced GetPlayerResponse calls.
+func (c *Client) CoalesceStats() CoalesceStats {
+	return c.coalescer.stats()
+}
+
+func (c *Client) getPlayerResponse(ctx context.Context, videoID string) (*PlayerResponse, error) {
+	ctx = ContextWithVideo(ctx, videoID)
+
+	key, keyed := c.playerCacheKey(videoID)
+	if c.Cache != nil && keyed {
+		value, err := c.Cache.Get(ctx, key)
+		switch {
+		case err == nil:
+			playerResponse, err := decodeCachedPlayerResponse(value, time.Now())
+			if err == nil {
+				cacheLookups.Inc("hit")
+				trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("cache.hit", true))
+				return playerResponse, nil
+			}
+			cacheLookups.Inc("error")
+			c.log(ctx, LevelWarn, "ignoring invalid cached player response", "key", key, "error", err)
+		case errors.Is(err, ErrCacheMiss):
+			cacheLookups.Inc("miss")
+		default:
+			cacheLookups.Inc("error")
+			c.log(ctx, LevelWarn, "cache get failed", "key", key, "error", err)
+		}
+	}
+
+	// The body is read into a pooled buffer, only its encoded copy for the cache outlives
+	// the call.
+	fetchedAt := time.Now()
+	var playerResponse *PlayerResponse
+	var cached []byte
+	var ttl time.Duration
+	err := c.call(ctx, "player", c.newPlayerRequest(videoID), func(body io.Reader) error {
+		return readPooled(body, func(body []byte) (err error) {
+			_, span := tracer.Start(ctx, "parse player response")
+			defer func() { endSpan(span, err) }()
+
+			if c.Strict || strictContext(ctx) {
+				drift, err := DetectDrift(body, PlayerResponse{})
+				if err != nil {
+					return err
+				}
+				if !drift.IsEmpty() {
+					return &SchemaDriftError{Drift: drift}
+				}
+			}
+
+			playerResponse, err = parsePlayerResponse(body)
+			if err != nil {
+				return err
+			}
+			if ttl = playerResponseTTL(playerResponse); c.Cache != nil && ttl > 0 {
+				cached = encodeCachedPlayerResponse(body, fetchedAt)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.keepSession(ctx, playerResponse)
+
+	// The resolution gave the video its proxy, if it had none.
+	if key, keyed = c.playerCacheKey(videoID); cached != nil && keyed {
+		if err := c.Cache.Set(ctx, key, cached, ttl); err != nil {
+			c.log(ctx, LevelWarn, "cache set failed", "key", key, "error", err)
+		}
+	}
+	return playerResponse, nil
+}
+
+// InvalidatePlayerResponse drops the cached player response of the video, e.g. when its
+// media URLs are refused before they should expire.
+func (c *Client) InvalidatePlayerResponse(ctx context.Context, videoID string) error {
+	key, keyed := c.playerCacheKey(videoID)
+	if c.Cache == nil || !keyed {
+		return nil
+	}
+	return c.Cache.Delete(ctx, key)
+}
+
+// get fetches the URL, e.g. a manifest or a caption track.
+func (c *Client) get(ctx context.Context, url string) ([]byte, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("User-Agent", c.Profile.UserAgent)
+
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, newStatusError(response, time.Now())
+	}
+	return io.ReadAll(response.Body)
+}
+
+// keepSession stores the visitor data of the player response, or rotates the session if
+// YT asks to sign in to confirm it is not a bot.
+func (c *Client) keepSession(ctx context.Context, playerResponse *PlayerResponse) {
+	if c.Sessions == nil {
+		return
+	}
+	if playerResponse.PlayabilityStatus.Status == "LOGIN_REQUIRED" {
+		c.log(ctx, LevelInfo, "rotating the session", "reason", playerResponse.PlayabilityStatus.Reason)
+		if err := c.Sessions.Rotate(); err != nil {
+			c.log(ctx, LevelError, "failed to save the session", "error", err)
+		}
+		return
+	}
+	c.updateSession(ctx, playerResponse.ResponseContext.VisitorData, nil)
+}
+
+func (c *Client) updateSession(ctx context.Context, visitorData string, cookies []*http.Cookie) {
+	if c.Sessions == nil {
+		return
+	}
+	if err := c.Sessions.update(visitorData, cookies); err != nil {
+		c.log(ctx, LevelError, "failed to save the session", "error", err)
+	}
+}
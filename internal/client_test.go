@@ -0,0 +1,223 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// innertubeRequest is a request received by an innertubeServer.
+type innertubeRequest struct {
+	Path   string
+	Header http.Header
+	Body   PlayerRequest
+}
+
+// innertubeServer answers every request with the player response and records the requests.
+type innertubeServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []innertubeRequest
+}
+
+func newInnertubeServer(t *testing.T, playerResponse string, cookies ...*http.Cookie) *innertubeServer {
+	server := &innertubeServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		request := innertubeRequest{Path: r.URL.Path, Header: r.Header.Clone()}
+		if err := json.Unmarshal(body, &request.Body); err != nil {
+			t.Errorf("invalid request body %s: %v", body, err)
+		}
+		server.mu.Lock()
+		server.requests = append(server.requests, request)
+		server.mu.Unlock()
+
+		for _, cookie := range cookies {
+			http.SetCookie(w, cookie)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, playerResponse)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (s *innertubeServer) received() []innertubeRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]innertubeRequest(nil), s.requests...)
+}
+
+const okPlayerResponse = `{
+	"responseContext": {"visitorData": "CgtWaXNpdG9yRGF0YQ"},
+	"playabilityStatus": {"status": "OK"},
+	"videoDetails": {"videoId": "dQw4w9WgXcQ", "title": "Test"}
+}`
+
+func TestClientPlayerRequest(t *testing.T) {
+	tests := []struct {
+		name             string
+		profile          ClientProfile
+		language         string
+		region           string
+		utcOffsetMinutes int
+		headers          http.Header
+		userAgent        string
+	}{
+		{
+			name:      "default",
+			profile:   AndroidTestSuiteProfile,
+			language:  "en",
+			region:    "US",
+			userAgent: AndroidTestSuiteProfile.UserAgent,
+		},
+		{
+			name:             "android with locale",
+			profile:          AndroidProfile,
+			language:         "de",
+			region:           "DE",
+			utcOffsetMinutes: 120,
+			userAgent:        AndroidProfile.UserAgent,
+		},
+		{
+			name:             "ios with negative offset",
+			profile:          IOSProfile,
+			language:         "pt-BR",
+			region:           "BR",
+			utcOffsetMinutes: -180,
+			userAgent:        IOSProfile.UserAgent,
+		},
+		{
+			name:      "custom headers",
+			profile:   AndroidTestSuiteProfile,
+			language:  "en",
+			region:    "GB",
+			headers:   http.Header{"User-Agent": {"test-agent"}, "X-Test": {"1"}},
+			userAgent: "test-agent",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newInnertubeServer(t, okPlayerResponse)
+			client := NewClient()
+			client.BaseURL = server.URL
+			client.Profile = test.profile
+			client.Language = test.language
+			client.Region = test.region
+			client.UTCOffsetMinutes = test.utcOffsetMinutes
+			if test.headers != nil {
+				client.Headers = test.headers
+			}
+
+			if _, err := client.GetPlayerResponse(context.Background(), "dQw4w9WgXcQ"); err != nil {
+				t.Fatal(err)
+			}
+
+			requests := server.received()
+			if len(requests) != 1 {
+				t.Fatalf("got %d requests, want 1", len(requests))
+			}
+			request := requests[0]
+
+			if request.Path != "/player" {
+				t.Errorf("path = %s, want /player", request.Path)
+			}
+			if contentType := request.Header.Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("Content-Type = %s, want application/json", contentType)
+			}
+			if userAgent := request.Header.Get("User-Agent"); userAgent != test.userAgent {
+				t.Errorf("User-Agent = %s, want %s", userAgent, test.userAgent)
+			}
+			for key := range test.headers {
+				if request.Header.Get(key) != test.headers.Get(key) {
+					t.Errorf("%s = %s, want %s", key, request.Header.Get(key), test.headers.Get(key))
+				}
+			}
+
+			want := PlayerRequest{
+				Context: InnertubeContext{Client: InnertubeClient{
+					ClientName:        test.profile.Name,
+					ClientVersion:     test.profile.Version,
+					AndroidSdkVersion: test.profile.AndroidSdkVersion,
+					Hl:                test.language,
+					Gl:                test.region,
+					UtcOffsetMinutes:  test.utcOffsetMinutes,
+				}},
+				VideoId: "dQw4w9WgXcQ",
+			}
+			if request.Body != want {
+				t.Errorf("request body = %+v, want %+v", request.Body, want)
+			}
+		})
+	}
+}
+
+func TestClientVisitorData(t *testing.T) {
+	server := newInnertubeServer(t, okPlayerResponse, &http.Cookie{Name: "VISITOR_INFO1_LIVE", Value: "abc"})
+	client := NewClient()
+	client.BaseURL = server.URL
+	ctx := context.Background()
+
+	if _, err := client.GetPlayerResponse(ctx, "dQw4w9WgXcQ"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Next(ctx, NextRequest{VideoId: "dQw4w9WgXcQ"}); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := server.received()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	if visitorID := requests[0].Header.Get("X-Goog-Visitor-Id"); visitorID != "" {
+		t.Errorf("first request X-Goog-Visitor-Id = %s, want none", visitorID)
+	}
+	if cookie := requests[0].Header.Get("Cookie"); cookie != "" {
+		t.Errorf("first request Cookie = %s, want none", cookie)
+	}
+
+	if requests[1].Path != "/next" {
+		t.Errorf("path = %s, want /next", requests[1].Path)
+	}
+	if visitorID := requests[1].Header.Get("X-Goog-Visitor-Id"); visitorID != "CgtWaXNpdG9yRGF0YQ" {
+		t.Errorf("second request X-Goog-Visitor-Id = %s, want CgtWaXNpdG9yRGF0YQ", visitorID)
+	}
+	if cookie := requests[1].Header.Get("Cookie"); cookie != "VISITOR_INFO1_LIVE=abc" {
+		t.Errorf("second request Cookie = %s, want VISITOR_INFO1_LIVE=abc", cookie)
+	}
+	if requests[1].Body.Context.Client.ClientName != AndroidTestSuiteProfile.Name {
+		t.Errorf("next request client = %s, want %s", requests[1].Body.Context.Client.ClientName, AndroidTestSuiteProfile.Name)
+	}
+
+	if session := client.Sessions.Session(); session.VisitorData != "CgtWaXNpdG9yRGF0YQ" || session.Requests != 2 {
+		t.Errorf("session = %+v, want the visitor data after 2 requests", session)
+	}
+}
+
+func TestClientNoSessions(t *testing.T) {
+	server := newInnertubeServer(t, okPlayerResponse, &http.Cookie{Name: "VISITOR_INFO1_LIVE", Value: "abc"})
+	client := NewClient()
+	client.BaseURL = server.URL
+	client.Sessions = nil
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetPlayerResponse(context.Background(), "dQw4w9WgXcQ"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, request := range server.received() {
+		if request.Header.Get("X-Goog-Visitor-Id") != "" || request.Header.Get("Cookie") != "" {
+			t.Errorf("request without sessions sent visitor data %q and cookies %q",
+				request.Header.Get("X-Goog-Visitor-Id"), request.Header.Get("Cookie"))
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CoalesceStats counts the player response lookups of a client.
+type CoalesceStats struct {
+	// Lookups is the number of GetPlayerResponse calls.
+	Lookups uint64 `json:"lookups"`
+	// Coalesced is the number of lookups which shared the upstream call of a concurrent
+	// lookup of the same video instead of making their own.
+	Coalesced uint64 `json:"coalesced"`
+}
+
+type coalescedCall struct {
+	done     chan struct{}
+	response *PlayerResponse
+	err      error
+}
+
+// coalescer shares one upstream call between the concurrent lookups of the same video.
+type coalescer struct {
+	mu    sync.Mutex
+	calls map[string]*coalescedCall
+
+	lookups   atomic.Uint64
+	coalesced atomic.Uint64
+}
+
+// do calls lookup for the video unless a lookup of the video is in flight, in which case
+// it waits for its result. The shared call outlives the cancellation of the caller that
+// started it, while every caller stops waiting when its own context is done.
+func (c *coalescer) do(ctx context.Context, videoID string, lookup func(ctx context.Context) (*PlayerResponse, error)) (*PlayerResponse, error) {
+	c.lookups.Add(1)
+
+	c.mu.Lock()
+	call, found := c.calls[videoID]
+	if found {
+		c.mu.Unlock()
+		c.coalesced.Add(1)
+		coalescedLookups.Inc()
+	} else {
+		if c.calls == nil {
+			c.calls = map[string]*coalescedCall{}
+		}
+		call = &coalescedCall{done: make(chan struct{})}
+		c.calls[videoID] = call
+		c.mu.Unlock()
+
+		go func() {
+			call.response, call.err = lookup(detachedContext{ctx})
+
+			c.mu.Lock()
+			delete(c.calls, videoID)
+			c.mu.Unlock()
+			close(call.done)
+		}()
+	}
+
+	select {
+	case <-call.done:
+		return call.response, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *coalescer) stats() CoalesceStats {
+	return CoalesceStats{Lookups: c.lookups.Load(), Coalesced: c.coalesced.Load()}
+}
+
+// detachedContext keeps the values of the parent context but not its cancellation.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
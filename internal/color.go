@@ -0,0 +1,81 @@
+package internal
+
+import "strings"
+
+// DynamicRange is the dynamic range class of a video stream.
+type DynamicRange string
+
+const (
+	DynamicRangeNone  DynamicRange = ""
+	DynamicRangeSDR   DynamicRange = "SDR"
+	DynamicRangeHDR10 DynamicRange = "HDR10"
+	DynamicRangeHLG   DynamicRange = "HLG"
+)
+
+// IsHDR reports whether the stream needs an HDR display or tone-mapping.
+func (r DynamicRange) IsHDR() bool {
+	return r == DynamicRangeHDR10 || r == DynamicRangeHLG
+}
+
+// ToneMapping hints clients how to map an HDR stream onto an SDR display.
+type ToneMapping struct {
+	// Transfer is the source transfer function: pq or hlg.
+	Transfer string `json:"transfer"`
+	// Primaries is the source color gamut, e.g. bt2020.
+	Primaries string `json:"primaries"`
+	// Matrix is the source YCbCr matrix, e.g. bt2020nc.
+	Matrix string `json:"matrix"`
+}
+
+// colorName shortens YT color enum values, e.g. COLOR_PRIMARIES_BT2020 to bt2020.
+func colorName(value string, prefix string) string {
+	return strings.ToLower(strings.TrimPrefix(value, prefix))
+}
+
+// DynamicRange classifies the stream by its transfer characteristics. Streams without
+// color info are SDR.
+func (c ColorInfo) DynamicRange() DynamicRange {
+	switch c.TransferCharacteristics {
+	case "COLOR_TRANSFER_CHARACTERISTICS_SMPTEST2084":
+		return DynamicRangeHDR10
+	case "COLOR_TRANSFER_CHARACTERISTICS_ARIB_STD_B67":
+		return DynamicRangeHLG
+	}
+	return DynamicRangeSDR
+}
+
+// ToneMapping returns the tone-mapping hint for HDR streams, nil for SDR ones.
+func (c ColorInfo) ToneMapping() *ToneMapping {
+	transfer := ""
+	switch c.DynamicRange() {
+	case DynamicRangeHDR10:
+		transfer = "pq"
+	case DynamicRangeHLG:
+		transfer = "hlg"
+	default:
+		return nil
+	}
+
+	return &ToneMapping{
+		Transfer:  transfer,
+		Primaries: colorName(c.Primaries, "COLOR_PRIMARIES_"),
+		Matrix:    colorName(c.MatrixCoefficients, "COLOR_MATRIX_COEFFICIENTS_"),
+	}
+}
+
+// classifyColor fills in the dynamic range and tone-mapping hints of all video streams.
+func (d *StreamingData) classifyColor() {
+	for i := range d.Formats {
+		format := &d.Formats[i]
+		format.DynamicRange = format.ColorInfo.DynamicRange()
+		format.ToneMapping = format.ColorInfo.ToneMapping()
+	}
+	for i := range d.AdaptiveFormats {
+		format := &d.AdaptiveFormats[i]
+		if !format.IsVideo() {
+			continue
+		}
+		format.DynamicRange = format.ColorInfo.DynamicRange()
+		format.ToneMapping = format.ColorInfo.ToneMapping()
+	}
+}
@@ -0,0 +1,198 @@
+package internal
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DescriptorVersion is bumped on every incompatible change of the Descriptor schema.
+const DescriptorVersion = 1
+
+// Descriptor is the compact, stable view of a player response consumed by clients.
+type Descriptor struct {
+	Version         int                 `json:"version"`
+	Id              string              `json:"id"`
+	Title           string              `json:"title"`
+	Author          string              `json:"author"`
+	DurationSeconds int                 `json:"durationSeconds"`
+	Playability     string              `json:"playability"`
+	Thumbnails      []Thumbnail         `json:"thumbnails"`
+	Streams         DescriptorStreams   `json:"streams"`
+	Captions        []DescriptorCaption `json:"captions"`
+	Live            DescriptorLive      `json:"live"`
+	ExpiresAt       *time.Time          `json:"expiresAt,omitempty"`
+}
+
+// DescriptorStreams holds the chosen streams: separate video and audio for clients able
+// to mux adaptive streams, and the best muxed format as a fallback.
+type DescriptorStreams struct {
+	Video *DescriptorStream `json:"video,omitempty"`
+	Audio *DescriptorStream `json:"audio,omitempty"`
+	Muxed *DescriptorStream `json:"muxed,omitempty"`
+}
+
+type DescriptorStream struct {
+	Itag          int          `json:"itag"`
+	URL           string       `json:"url"`
+	MimeType      string       `json:"mimeType"`
+	Bitrate       int          `json:"bitrate"`
+	ContentLength int64        `json:"contentLength,omitempty"`
+	Width         int          `json:"width,omitempty"`
+	Height        int          `json:"height,omitempty"`
+	FPS           int          `json:"fps,omitempty"`
+	DynamicRange  DynamicRange `json:"dynamicRange,omitempty"`
+	ToneMapping   *ToneMapping `json:"toneMapping,omitempty"`
+	AudioLayout   AudioLayout  `json:"audioLayout,omitempty"`
+	AudioChannels int          `json:"audioChannels,omitempty"`
+	SampleRate    int          `json:"sampleRate,omitempty"`
+	Language      string       `json:"language,omitempty"`
+}
+
+type DescriptorCaption struct {
+	Language  string `json:"language"`
+	Name      string `json:"name"`
+	Automatic bool   `json:"automatic"`
+	URL       string `json:"url"`
+}
+
+type DescriptorLive struct {
+	IsLive          bool   `json:"isLive"`
+	IsUpcoming      bool   `json:"isUpcoming"`
+	WasLive         bool   `json:"wasLive"`
+	HlsManifestUrl  string `json:"hlsManifestUrl,omitempty"`
+	DashManifestUrl string `json:"dashManifestUrl,omitempty"`
+}
+
+// String returns the plain text, joining the runs of formatted text.
+func (t Text) String() string {
+	if t.SimpleText != "" {
+		return t.SimpleText
+	}
+
+	var builder strings.Builder
+	for _, run := range t.Runs {
+		builder.WriteString(run.Text)
+	}
+	return builder.String()
+}
+
+// NewDescriptor builds the descriptor of the player response of the video, choosing the
+// streams with the selector. The expiry is computed relative to now. The id falls back to
+// the requested video ID, as unplayable videos come without video details.
+func NewDescriptor(videoID string, response *PlayerResponse, selector Selector, now time.Time) *Descriptor {
+	details := response.VideoDetails
+	streamingData := response.StreamingData
+
+	descriptor := &Descriptor{
+		Version:     DescriptorVersion,
+		Id:          details.VideoId,
+		Title:       details.Title,
+		Author:      details.Author,
+		Playability: response.PlayabilityStatus.Status,
+		Thumbnails:  details.Thumbnail.Thumbnails,
+		Captions:    []DescriptorCaption{},
+		Live: DescriptorLive{
+			IsLive:          details.IsLive,
+			IsUpcoming:      details.IsUpcoming,
+			WasLive:         details.IsLiveContent && !details.IsLive && !details.IsUpcoming,
+			HlsManifestUrl:  streamingData.HlsManifestUrl,
+			DashManifestUrl: streamingData.DashManifestUrl,
+		},
+	}
+
+	if descriptor.Id == "" {
+		descriptor.Id = videoID
+	}
+	if descriptor.Thumbnails == nil {
+		descriptor.Thumbnails = []Thumbnail{}
+	}
+
+	if duration, err := strconv.Atoi(details.LengthSeconds); err == nil {
+		descriptor.DurationSeconds = duration
+	}
+
+	if expiresIn, err := strconv.Atoi(streamingData.ExpiresInSeconds); err == nil {
+		expiresAt := now.Add(time.Duration(expiresIn) * time.Second).UTC()
+		descriptor.ExpiresAt = &expiresAt
+	}
+
+	if selection, err := selector.Select(streamingData); err == nil {
+		if selection.Video != nil {
+			descriptor.Streams.Video = newAdaptiveDescriptorStream(selection.Video)
+		}
+		if selection.Audio != nil {
+			descriptor.Streams.Audio = newAdaptiveDescriptorStream(selection.Audio)
+		}
+	}
+
+	if muxed := selector.selectMuxed(streamingData.Formats); muxed != nil {
+		descriptor.Streams.Muxed = newMuxedDescriptorStream(muxed)
+	}
+
+	for _, track := range response.Captions.PlayerCaptionsTracklistRenderer.CaptionTracks {
+		descriptor.Captions = append(descriptor.Captions, DescriptorCaption{
+			Language:  track.LanguageCode,
+			Name:      track.Name.String(),
+			Automatic: track.Kind == "asr",
+			URL:       track.BaseUrl,
+		})
+	}
+
+	return descriptor
+}
+
+func parseContentLength(value string) int64 {
+	contentLength, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return contentLength
+}
+
+func newAdaptiveDescriptorStream(format *AdaptiveFormat) *DescriptorStream {
+	stream := &DescriptorStream{
+		Itag:          format.Itag,
+		URL:           format.URL,
+		MimeType:      format.MimeType,
+		Bitrate:       format.Bitrate,
+		ContentLength: parseContentLength(format.ContentLength),
+	}
+
+	if format.IsVideo() {
+		stream.Width = format.Width
+		stream.Height = format.Height
+		stream.FPS = format.FPS
+		stream.DynamicRange = format.DynamicRange
+		stream.ToneMapping = format.ToneMapping
+	}
+
+	if format.IsAudio() {
+		stream.AudioLayout = format.AudioLayout()
+		stream.AudioChannels = format.AudioChannels
+		stream.SampleRate = format.SampleRate()
+		if format.AudioTrack != nil {
+			stream.Language = format.AudioTrack.Language()
+		}
+	}
+
+	return stream
+}
+
+func newMuxedDescriptorStream(format *Format) *DescriptorStream {
+	return &DescriptorStream{
+		Itag:          format.Itag,
+		URL:           format.URL,
+		MimeType:      format.MimeType,
+		Bitrate:       format.Bitrate,
+		ContentLength: parseContentLength(format.ContentLength),
+		Width:         format.Width,
+		Height:        format.Height,
+		FPS:           format.FPS,
+		DynamicRange:  format.DynamicRange,
+		ToneMapping:   format.ToneMapping,
+		AudioLayout:   format.AudioLayout(),
+		AudioChannels: format.AudioChannels,
+		SampleRate:    format.SampleRate(),
+	}
+}
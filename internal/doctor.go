@@ -0,0 +1,129 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultCanaryVideoId is a long-lived public video used to check the resolution works.
+const DefaultCanaryVideoId = "jNQXAC9IVRw"
+
+// DoctorCheck is the outcome of one step of the end to end check.
+type DoctorCheck struct {
+	Name     string        `json:"name"`
+	OK       bool          `json:"ok"`
+	Details  string        `json:"details,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// DoctorReport is the outcome of the end to end check of a canary video.
+type DoctorReport struct {
+	VideoId string        `json:"videoId"`
+	Checks  []DoctorCheck `json:"checks"`
+	Drift   *SchemaDrift  `json:"drift,omitempty"`
+}
+
+// OK reports whether all checks passed.
+func (r *DoctorReport) OK() bool {
+	for _, check := range r.Checks {
+		if !check.OK {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *DoctorReport) check(name string, start time.Time, err error, details string) bool {
+	check := DoctorCheck{Name: name, OK: err == nil, Details: details, Duration: time.Since(start)}
+	if err != nil {
+		check.Details = err.Error()
+	}
+	r.Checks = append(r.Checks, check)
+	return check.OK
+}
+
+// Doctor resolves the canary video end to end: requests the player response, compares it
+// with the model, checks it is playable and that its media can be fetched.
+func (c *Client) Doctor(ctx context.Context, videoID string) *DoctorReport {
+	report := &DoctorReport{VideoId: videoID}
+
+	start := time.Now()
+	responseBody, err := c.GetPlayerResponseBody(ctx, videoID)
+	if !report.check("player request", start, err, fmt.Sprintf("%d bytes", len(responseBody))) {
+		return report
+	}
+
+	start = time.Now()
+	drift, err := DetectDrift(responseBody, PlayerResponse{})
+	details := ""
+	if err == nil {
+		report.Drift = drift
+		details = fmt.Sprintf("%d unknown, %d missing fields", len(drift.Unknown), len(drift.Missing))
+	}
+	report.check("schema drift", start, err, details)
+
+	start = time.Now()
+	response, err := parsePlayerResponse(responseBody)
+	if !report.check("parse", start, err, "") {
+		return report
+	}
+	c.keepSession(ctx, response)
+
+	start = time.Now()
+	status := response.PlayabilityStatus
+	err = nil
+	if status.Status != "OK" {
+		err = fmt.Errorf("%s: %s", status.Status, status.Reason)
+	}
+	if !report.check("playability", start, err, status.Status) {
+		return report
+	}
+
+	start = time.Now()
+	selection, err := Selector{}.Select(response.StreamingData)
+	if !report.check("stream selection", start, err, "") {
+		return report
+	}
+
+	format := selection.Video
+	if format == nil {
+		format = selection.Audio
+	}
+
+	start = time.Now()
+	err = c.checkMedia(ContextWithMedia(ctx, videoID), format.URL)
+	report.check("media fetch", start, err, fmt.Sprintf("itag %d", format.Itag))
+
+	return report
+}
+
+// checkMedia fetches the first byte of the media URL.
+func (c *Client) checkMedia(ctx context.Context, url string) error {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Range", "bytes=0-0")
+	request.Header.Set("User-Agent", c.Profile.UserAgent)
+
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("media request failed with status code: %d", response.StatusCode)
+	}
+	return nil
+}
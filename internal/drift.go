@@ -0,0 +1,239 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SchemaDrift lists the differences between a response and its Go model. Paths are dotted
+// JSON field names with [] marking list elements, e.g. streamingData.formats[].url.
+type SchemaDrift struct {
+	// Unknown fields are present in the response but not in the model.
+	Unknown []string `json:"unknown"`
+	// Missing fields are expected by the model but absent from the response. Fields of
+	// list elements are missing only when no element has them.
+	Missing []string `json:"missing"`
+}
+
+// IsEmpty reports whether the response matches the model.
+func (d *SchemaDrift) IsEmpty() bool {
+	return len(d.Unknown) == 0 && len(d.Missing) == 0
+}
+
+func (d *SchemaDrift) String() string {
+	var builder strings.Builder
+	for _, path := range d.Unknown {
+		builder.WriteString("+ " + path + "\n")
+	}
+	for _, path := range d.Missing {
+		builder.WriteString("- " + path + "\n")
+	}
+	return builder.String()
+}
+
+// SchemaDriftError is returned by a strict Client, or for a strict call, when the response
+// drifted from the model.
+type SchemaDriftError struct {
+	Drift *SchemaDrift
+}
+
+func (e *SchemaDriftError) Error() string {
+	return fmt.Sprintf("player response schema drift: %d unknown and %d missing fields\n%s",
+		len(e.Drift.Unknown), len(e.Drift.Missing), strings.TrimSuffix(e.Drift.String(), "\n"))
+}
+
+// DetectDrift compares the JSON response body with the model type of the value.
+func DetectDrift(responseBody []byte, model interface{}) (*SchemaDrift, error) {
+	var value interface{}
+	if err := json.Unmarshal(responseBody, &value); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	walker := driftWalker{
+		unknown:  map[string]bool{},
+		expected: map[string]bool{},
+		seen:     map[string]bool{},
+	}
+	walker.walk(value, reflect.TypeOf(model), "")
+
+	drift := &SchemaDrift{Unknown: []string{}, Missing: []string{}}
+	for path := range walker.unknown {
+		drift.Unknown = append(drift.Unknown, path)
+	}
+	for path := range walker.expected {
+		if !walker.seen[path] {
+			drift.Missing = append(drift.Missing, path)
+		}
+	}
+	sort.Strings(drift.Unknown)
+	sort.Strings(drift.Missing)
+
+	return drift, nil
+}
+
+type strictKey struct{}
+
+// ContextWithStrict makes the GetPlayerResponse calls with the context fail with a
+// SchemaDriftError when the player response drifted from the model, as Client.Strict
+// does for all the calls of a client.
+func ContextWithStrict(ctx context.Context) context.Context {
+	return context.WithValue(ctx, strictKey{}, true)
+}
+
+func strictContext(ctx context.Context) bool {
+	strict, _ := ctx.Value(strictKey{}).(bool)
+	return strict
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// volatileDriftPaths are the sections YT varies between clients, experiments and videos,
+// or which aren't modelled at all. They are neither compared nor reported unknown or missing.
+var volatileDriftPaths = map[string]bool{
+	"captions":                     true,
+	"playerConfig.exoPlayerConfig": true,
+
+	"adBreakHeartbeatParams":              true,
+	"adPlacements":                        true,
+	"adSlots":                             true,
+	"annotations":                         true,
+	"attestation":                         true,
+	"auxiliaryUi":                         true,
+	"cards":                               true,
+	"endscreen":                           true,
+	"frameworkUpdates":                    true,
+	"heartbeatParams":                     true,
+	"messages":                            true,
+	"microformat":                         true,
+	"onResponseReceivedEndpoints":         true,
+	"overlay":                             true,
+	"paidContentOverlay":                  true,
+	"playerAds":                           true,
+	"playerSettingsMenuData":              true,
+	"storyboards":                         true,
+	"trackingParams":                      true,
+	"videoQualityPromoSupportedRenderers": true,
+}
+
+// optionalDriftPaths are the sections absent from the responses of unplayable videos.
+var optionalDriftPaths = map[string]bool{
+	"playbackTracking": true,
+	"playerConfig":     true,
+	"streamingData":    true,
+	"videoDetails":     true,
+}
+
+type driftWalker struct {
+	unknown  map[string]bool
+	expected map[string]bool
+	seen     map[string]bool
+}
+
+func joinPath(parent string, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func (w *driftWalker) walk(value interface{}, t reflect.Type, path string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	// Opaque values are not modelled field by field.
+	if t == rawMessageType || t.Kind() == reflect.Interface {
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			w.walkStruct(v, t, path)
+		case reflect.Map:
+			for key, item := range v {
+				w.walk(item, t.Elem(), joinPath(path, key))
+			}
+		}
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return
+		}
+		for _, item := range v {
+			w.walk(item, t.Elem(), path+"[]")
+		}
+	}
+}
+
+// modelFields maps the JSON names of the struct fields to the fields and whether they are
+// expected. Fields of embedded structs are promoted unless shadowed, as in encoding/json.
+// Fields tagged omitempty, pointers, slices and maps are optional, as YT leaves out empty
+// values.
+func modelFields(t reflect.Type) (map[string]reflect.StructField, map[string]bool) {
+	fields := map[string]reflect.StructField{}
+	expected := map[string]bool{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Tag.Get("json") == "" && field.Type.Kind() == reflect.Struct {
+			embeddedFields, embeddedExpected := modelFields(field.Type)
+			for name, embeddedField := range embeddedFields {
+				if _, shadowed := fields[name]; !shadowed {
+					fields[name] = embeddedField
+					expected[name] = embeddedExpected[name]
+				}
+			}
+		}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" || (field.Anonymous && name == "") {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fields[name] = field
+		switch field.Type.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map:
+			expected[name] = false
+		default:
+			expected[name] = !strings.Contains(options, "omitempty")
+		}
+	}
+
+	return fields, expected
+}
+
+func (w *driftWalker) walkStruct(object map[string]interface{}, t reflect.Type, path string) {
+	fields, expected := modelFields(t)
+	for name, isExpected := range expected {
+		fieldPath := joinPath(path, name)
+		if isExpected && !volatileDriftPaths[fieldPath] && !optionalDriftPaths[fieldPath] {
+			w.expected[fieldPath] = true
+		}
+	}
+
+	for key, item := range object {
+		fieldPath := joinPath(path, key)
+		if volatileDriftPaths[fieldPath] {
+			continue
+		}
+		field, ok := fields[key]
+		if !ok {
+			w.unknown[fieldPath] = true
+			continue
+		}
+
+		w.seen[fieldPath] = true
+		w.walk(item, field.Type, fieldPath)
+	}
+}
@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func readDriftFixture(t *testing.T, name string) map[string]interface{} {
+	t.Helper()
+	body, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var response map[string]interface{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+func detectDrift(t *testing.T, response map[string]interface{}) *SchemaDrift {
+	t.Helper()
+	body, err := json.Marshal(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drift, err := DetectDrift(body, PlayerResponse{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return drift
+}
+
+func TestDetectDriftFixtures(t *testing.T) {
+	for _, name := range []string{"player_response.json", "player_response_ios.json", "player_response_login_required.json"} {
+		t.Run(name, func(t *testing.T) {
+			if drift := detectDrift(t, readDriftFixture(t, name)); !drift.IsEmpty() {
+				t.Errorf("unexpected drift:\n%s", drift)
+			}
+		})
+	}
+}
+
+func TestDetectDrift(t *testing.T) {
+	object := func(value interface{}) map[string]interface{} {
+		return value.(map[string]interface{})
+	}
+	section := func(response map[string]interface{}, key string) map[string]interface{} {
+		return object(response[key])
+	}
+	eachFormat := func(response map[string]interface{}, change func(format map[string]interface{})) {
+		for _, format := range section(response, "streamingData")["adaptiveFormats"].([]interface{}) {
+			change(object(format))
+		}
+	}
+
+	tests := []struct {
+		name        string
+		change      func(response map[string]interface{})
+		wantUnknown []string
+		wantMissing []string
+	}{
+		{
+			name: "new format field",
+			change: func(response map[string]interface{}) {
+				object(section(response, "streamingData")["adaptiveFormats"].([]interface{})[0])["xtags"] = "CgcKAnZ0EgEx"
+			},
+			wantUnknown: []string{"streamingData.adaptiveFormats[].xtags"},
+		},
+		{
+			name: "new section",
+			change: func(response map[string]interface{}) {
+				response["playerOverlays"] = map[string]interface{}{}
+			},
+			wantUnknown: []string{"playerOverlays"},
+		},
+		{
+			name: "new modelled section field",
+			change: func(response map[string]interface{}) {
+				object(section(response, "playerConfig")["audioConfig"])["muteOnStart"] = true
+			},
+			wantUnknown: []string{"playerConfig.audioConfig.muteOnStart"},
+		},
+		{
+			name: "missing fields",
+			change: func(response map[string]interface{}) {
+				delete(section(response, "videoDetails"), "title")
+				delete(section(response, "streamingData"), "expiresInSeconds")
+				eachFormat(response, func(format map[string]interface{}) { delete(format, "mimeType") })
+			},
+			wantMissing: []string{"streamingData.adaptiveFormats[].mimeType", "streamingData.expiresInSeconds", "videoDetails.title"},
+		},
+		{
+			name: "field missing from some formats",
+			change: func(response map[string]interface{}) {
+				delete(object(section(response, "streamingData")["adaptiveFormats"].([]interface{})[0]), "contentLength")
+			},
+		},
+		{
+			name: "optional fields",
+			change: func(response map[string]interface{}) {
+				delete(section(response, "videoDetails"), "keywords")
+				delete(section(response, "playabilityStatus"), "reason")
+				delete(section(response, "streamingData"), "formats")
+				eachFormat(response, func(format map[string]interface{}) {
+					delete(format, "audioQuality")
+					delete(format, "audioTrack")
+					delete(format, "colorInfo")
+				})
+			},
+		},
+		{
+			name: "volatile sections",
+			change: func(response map[string]interface{}) {
+				object(section(response, "playerConfig")["exoPlayerConfig"])["useNewBandwidthMeter"] = true
+				delete(object(section(response, "playerConfig")["exoPlayerConfig"]), "useExoPlayer")
+				section(response, "captions")["playerCaptionsTracklistRenderer"] = map[string]interface{}{"translationLanguages": []interface{}{}}
+				response["storyboards"] = map[string]interface{}{"playerStoryboardSpecRenderer": map[string]interface{}{}}
+				response["microformat"] = map[string]interface{}{}
+			},
+		},
+		{
+			name: "unplayable",
+			change: func(response map[string]interface{}) {
+				for _, key := range []string{"streamingData", "playbackTracking", "playerConfig", "videoDetails", "captions"} {
+					delete(response, key)
+				}
+			},
+		},
+		{
+			name: "missing required section",
+			change: func(response map[string]interface{}) {
+				delete(response, "playabilityStatus")
+			},
+			wantMissing: []string{"playabilityStatus"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := readDriftFixture(t, "player_response.json")
+			test.change(response)
+			drift := detectDrift(t, response)
+
+			wantUnknown, wantMissing := test.wantUnknown, test.wantMissing
+			if wantUnknown == nil {
+				wantUnknown = []string{}
+			}
+			if wantMissing == nil {
+				wantMissing = []string{}
+			}
+			if !reflect.DeepEqual(drift.Unknown, wantUnknown) || !reflect.DeepEqual(drift.Missing, wantMissing) {
+				t.Errorf("drift:\n%swant unknown %v, missing %v", drift, wantUnknown, wantMissing)
+			}
+		})
+	}
+}
+
+func TestClientStrict(t *testing.T) {
+	server := newInnertubeServer(t, `{
+		"responseContext": {},
+		"playabilityStatus": {"status": "OK", "playableInEmbed": true, "newStatusField": 1}
+	}`)
+	client := NewClient()
+	client.BaseURL = server.URL
+	client.Coalesce = false
+
+	if _, err := client.GetPlayerResponse(context.Background(), "dQw4w9WgXcQ"); err != nil {
+		t.Fatalf("lenient call failed: %v", err)
+	}
+
+	_, err := client.GetPlayerResponse(ContextWithStrict(context.Background()), "dQw4w9WgXcQ")
+	var driftErr *SchemaDriftError
+	if !errors.As(err, &driftErr) {
+		t.Fatalf("strict call error = %v, want SchemaDriftError", err)
+	}
+	if want := []string{"playabilityStatus.newStatusField"}; !reflect.DeepEqual(driftErr.Drift.Unknown, want) {
+		t.Errorf("unknown = %v, want %v", driftErr.Drift.Unknown, want)
+	}
+
+	client.Strict = true
+	if _, err := client.GetPlayerResponse(context.Background(), "dQw4w9WgXcQ"); !errors.As(err, &driftErr) {
+		t.Errorf("strict client error = %v, want SchemaDriftError", err)
+	}
+}
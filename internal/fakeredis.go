@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FakeRedis is a local stand-in for a Redis server implementing the commands used by
+// the RedisCache: PING, AUTH, SELECT, GET, SET with EX/PX, DEL and QUIT.
+type FakeRedis struct {
+	// Password, if set, must be sent with AUTH before any other command.
+	Password string
+
+	cache MemoryCache
+}
+
+// Serve accepts the connections of the listener until it is closed.
+func (f *FakeRedis) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go f.serveConn(conn)
+	}
+}
+
+func (f *FakeRedis) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	authenticated := f.Password == ""
+	for {
+		request, err := readRESP(reader)
+		if err != nil {
+			return
+		}
+
+		args, ok := fakeRedisArgs(request)
+		if !ok {
+			fmt.Fprint(writer, "-ERR invalid command\r\n")
+			writer.Flush()
+			continue
+		}
+
+		command := strings.ToUpper(string(args[0]))
+		if !authenticated && command != "AUTH" {
+			fmt.Fprint(writer, "-NOAUTH Authentication required.\r\n")
+		} else {
+			if command == "AUTH" {
+				authenticated = string(args[len(args)-1]) == f.Password
+			}
+			f.execute(writer, command, args[1:], authenticated)
+		}
+		if err := writer.Flush(); err != nil || command == "QUIT" {
+			return
+		}
+	}
+}
+
+// fakeRedisArgs returns the arguments of a command, an array of bulk strings.
+func fakeRedisArgs(request interface{}) ([][]byte, bool) {
+	values, ok := request.([]interface{})
+	if !ok || len(values) == 0 {
+		return nil, false
+	}
+
+	args := make([][]byte, len(values))
+	for i, value := range values {
+		if args[i], ok = value.([]byte); !ok {
+			return nil, false
+		}
+	}
+	return args, true
+}
+
+func (f *FakeRedis) execute(writer *bufio.Writer, command string, args [][]byte, authenticated bool) {
+	ctx := context.Background()
+	arg := func(i int) string {
+		return string(args[i])
+	}
+
+	switch {
+	case command == "PING":
+		fmt.Fprint(writer, "+PONG\r\n")
+	case command == "QUIT", command == "SELECT" && len(args) == 1:
+		fmt.Fprint(writer, "+OK\r\n")
+	case command == "AUTH":
+		if !authenticated {
+			fmt.Fprint(writer, "-WRONGPASS invalid username-password pair\r\n")
+			return
+		}
+		fmt.Fprint(writer, "+OK\r\n")
+	case command == "GET" && len(args) == 1:
+		value, err := f.cache.Get(ctx, arg(0))
+		if errors.Is(err, ErrCacheMiss) {
+			fmt.Fprint(writer, "$-1\r\n")
+			return
+		}
+		fmt.Fprintf(writer, "$%d\r\n%s\r\n", len(value), value)
+	case command == "SET" && (len(args) == 2 || len(args) == 4):
+		ttl := 100 * 365 * 24 * time.Hour
+		if len(args) == 4 {
+			amount, err := strconv.ParseInt(arg(3), 10, 64)
+			switch unit := strings.ToUpper(arg(2)); {
+			case err != nil || amount <= 0:
+				fmt.Fprint(writer, "-ERR invalid expire time in 'set' command\r\n")
+				return
+			case unit == "EX":
+				ttl = time.Duration(amount) * time.Second
+			case unit == "PX":
+				ttl = time.Duration(amount) * time.Millisecond
+			default:
+				fmt.Fprint(writer, "-ERR syntax error\r\n")
+				return
+			}
+		}
+		value := append([]byte(nil), args[1]...)
+		_ = f.cache.Set(ctx, arg(0), value, ttl)
+		fmt.Fprint(writer, "+OK\r\n")
+	case command == "DEL" && len(args) > 0:
+		deleted := 0
+		for i := range args {
+			if _, err := f.cache.Get(ctx, arg(i)); err == nil {
+				deleted++
+			}
+			_ = f.cache.Delete(ctx, arg(i))
+		}
+		fmt.Fprintf(writer, ":%d\r\n", deleted)
+	default:
+		fmt.Fprintf(writer, "-ERR unknown command or wrong number of arguments for '%s'\r\n", strings.ToLower(command))
+	}
+}
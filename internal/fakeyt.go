@@ -0,0 +1,209 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// FakeYT serves the innertube player endpoint and googlevideo-style media URLs from a
+// fixture directory, so the tool and its clients can be tested offline.
+//
+// Each video is a subdirectory named by its ID containing:
+//
+//	player.json    the player response returned as is, e.g. captured with --record
+//	<itag>.<ext>   the media of the format with the itag, served with range support
+//	scenario.json  optional FakeScenario overriding the behavior for the video
+//
+// Stream URLs in player.json are rewritten to point to the fake server.
+type FakeYT struct {
+	Dir string
+	// Now returns the current time, used to expire media URLs.
+	Now func() time.Time
+}
+
+// FakeScenario configures the error cases of a fixture video.
+type FakeScenario struct {
+	// PlayerStatusCode fails the player endpoint with the HTTP status, e.g. 429.
+	PlayerStatusCode int `json:"playerStatusCode,omitempty"`
+	// MediaStatusCode fails the media URLs with the HTTP status, e.g. 403.
+	MediaStatusCode int `json:"mediaStatusCode,omitempty"`
+	// ExpiresInSeconds is the lifetime of the media URLs, 21600 by default.
+	ExpiresInSeconds int `json:"expiresInSeconds,omitempty"`
+}
+
+const fakeExpiresInSeconds = 21600
+
+// fakeVideoIdPattern matches the IDs mapping to fixture directories. It leaves out ".",
+// ".." and separators, so a request can't read outside Dir.
+var fakeVideoIdPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+func (f *FakeYT) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/youtubei/v1/player":
+		f.handlePlayer(w, r)
+	case "/videoplayback":
+		f.handleMedia(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *FakeYT) now() time.Time {
+	if f.Now != nil {
+		return f.Now()
+	}
+	return time.Now()
+}
+
+func (f *FakeYT) scenario(videoId string) (FakeScenario, error) {
+	scenario := FakeScenario{}
+	serializedScenario, err := os.ReadFile(filepath.Join(f.Dir, videoId, "scenario.json"))
+	if os.IsNotExist(err) {
+		return scenario, nil
+	}
+	if err != nil {
+		return scenario, err
+	}
+
+	if err := json.Unmarshal(serializedScenario, &scenario); err != nil {
+		return scenario, fmt.Errorf("failed to parse scenario of %s: %v", videoId, err)
+	}
+	return scenario, nil
+}
+
+func (f *FakeYT) handlePlayer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	request := PlayerRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !fakeVideoIdPattern.MatchString(request.VideoId) {
+		writeJSON(w, http.StatusOK, unavailablePlayerResponse())
+		return
+	}
+
+	scenario, err := f.scenario(request.VideoId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if scenario.PlayerStatusCode != 0 {
+		http.Error(w, http.StatusText(scenario.PlayerStatusCode), scenario.PlayerStatusCode)
+		return
+	}
+
+	serializedResponse, err := os.ReadFile(filepath.Join(f.Dir, request.VideoId, "player.json"))
+	if os.IsNotExist(err) {
+		writeJSON(w, http.StatusOK, unavailablePlayerResponse())
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Decode generically so fields unknown to PlayerResponse are served too.
+	var response map[string]interface{}
+	if err := json.Unmarshal(serializedResponse, &response); err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse player.json of %s: %v", request.VideoId, err), http.StatusInternalServerError)
+		return
+	}
+
+	expiresInSeconds := scenario.ExpiresInSeconds
+	if expiresInSeconds == 0 {
+		expiresInSeconds = fakeExpiresInSeconds
+	}
+
+	if streamingData, ok := response["streamingData"].(map[string]interface{}); ok {
+		streamingData["expiresInSeconds"] = strconv.Itoa(expiresInSeconds)
+		expire := f.now().Add(time.Duration(expiresInSeconds) * time.Second).Unix()
+		for _, key := range []string{"formats", "adaptiveFormats"} {
+			formats, _ := streamingData[key].([]interface{})
+			for _, item := range formats {
+				format, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				itag, _ := format["itag"].(float64)
+				format["url"] = fmt.Sprintf("http://%s/videoplayback?id=%s&itag=%d&expire=%d", r.Host, request.VideoId, int(itag), expire)
+				delete(format, "signatureCipher")
+			}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func unavailablePlayerResponse() PlayerResponse {
+	return PlayerResponse{
+		PlayabilityStatus: PlayabilityStatus{
+			Status: "ERROR",
+			Reason: "Video unavailable",
+		},
+	}
+}
+
+func (f *FakeYT) handleMedia(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	videoId := query.Get("id")
+	itag := query.Get("itag")
+	if !fakeVideoIdPattern.MatchString(videoId) {
+		http.NotFound(w, r)
+		return
+	}
+	if _, err := strconv.Atoi(itag); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	expire, err := strconv.ParseInt(query.Get("expire"), 10, 64)
+	if err != nil || f.now().Unix() > expire {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	scenario, err := f.scenario(videoId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if scenario.MediaStatusCode != 0 {
+		http.Error(w, http.StatusText(scenario.MediaStatusCode), scenario.MediaStatusCode)
+		return
+	}
+
+	matches, err := filepath.Glob(filepath.Join(f.Dir, videoId, itag+".*"))
+	if err != nil || len(matches) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	file, err := os.Open(matches[0])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// ServeContent answers HEAD and Range requests.
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
@@ -0,0 +1,230 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock is the adjustable time of a FakeYT.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+const fakePlayerJSON = `{
+	"responseContext": {},
+	"playabilityStatus": {"status": "OK"},
+	"videoDetails": {"videoId": "%s", "title": "Fake"},
+	"streamingData": {
+		"formats": [{"itag": 18, "mimeType": "video/mp4; codecs=\"avc1.42001E, mp4a.40.2\"", "width": 640, "height": 360, "url": "x"}],
+		"adaptiveFormats": []
+	}
+}`
+
+// writeFakeVideo adds a fixture video with a player response, the media of itag 18 and
+// the scenario if not empty.
+func writeFakeVideo(t *testing.T, dir, videoID string, media []byte, scenario string) {
+	t.Helper()
+	videoDir := filepath.Join(dir, videoID)
+	if err := os.MkdirAll(videoDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"player.json": []byte(strings.Replace(fakePlayerJSON, "%s", videoID, 1)),
+		"18.mp4":      media,
+	}
+	if scenario != "" {
+		files["scenario.json"] = []byte(scenario)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(videoDir, name), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// fakeYTServer serves the fixtures and counts the player requests.
+func fakeYTServer(t *testing.T, dir string, clock *fakeClock) (*httptest.Server, *int32) {
+	var playerRequests int32
+	fake := &FakeYT{Dir: dir, Now: clock.Now}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/youtubei/v1/player" {
+			atomic.AddInt32(&playerRequests, 1)
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &playerRequests
+}
+
+func TestFakeYTRejectsInvalidIDs(t *testing.T) {
+	dir := t.TempDir()
+	writeFakeVideo(t, dir, "vid1", []byte("media"), "")
+	// A file next to the fixture directory the IDs must not reach.
+	if err := os.WriteFile(filepath.Join(dir, "player.json"), []byte(`{"playabilityStatus":{"status":"OK"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fake, _ := fakeYTServer(t, dir, &fakeClock{now: time.Now()})
+
+	for _, videoID := range []string{"", ".", "..", "../vid1", "vid1/..", "vid1/../vid1", "v id", strings.Repeat("a", 65)} {
+		body, _ := json.Marshal(PlayerRequest{VideoId: videoID})
+		response, err := http.Post(fake.URL+"/youtubei/v1/player", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		playerResponse := PlayerResponse{}
+		err = json.NewDecoder(response.Body).Decode(&playerResponse)
+		response.Body.Close()
+		if err != nil || playerResponse.PlayabilityStatus.Status != "ERROR" {
+			t.Errorf("player of %q = %+v, %v, want unavailable", videoID, playerResponse.PlayabilityStatus, err)
+		}
+
+		expire := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+		response, err = http.Get(fake.URL + "/videoplayback?" + url.Values{"id": {videoID}, "itag": {"18"}, "expire": {expire}}.Encode())
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("media of %q = %d, want 404", videoID, response.StatusCode)
+		}
+	}
+}
+
+func TestServerAgainstFakeYT(t *testing.T) {
+	media := make([]byte, 1000)
+	for i := range media {
+		media[i] = byte(i)
+	}
+	dir := t.TempDir()
+	writeFakeVideo(t, dir, "vid1", media, "")
+	writeFakeVideo(t, dir, "gone", media, `{"mediaStatusCode": 403}`)
+	writeFakeVideo(t, dir, "limited", media, `{"playerStatusCode": 429}`)
+	clock := &fakeClock{now: time.Now()}
+	fake, playerRequests := fakeYTServer(t, dir, clock)
+
+	client := NewClient()
+	client.BaseURL = fake.URL + "/youtubei/v1"
+	client.Retry.MaxAttempts = 1
+	client.Sessions = nil
+	server := httptest.NewServer(NewServer(client))
+	defer server.Close()
+
+	get := func(path string, header http.Header) (*http.Response, []byte) {
+		t.Helper()
+		request, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for key := range header {
+			request.Header.Set(key, header.Get(key))
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return response, body
+	}
+
+	response, body := get("/v1/resolve?videoId=vid1", nil)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("resolve = %d %s", response.StatusCode, body)
+	}
+	descriptor := Descriptor{}
+	if err := json.Unmarshal(body, &descriptor); err != nil {
+		t.Fatal(err)
+	}
+	if descriptor.Id != "vid1" {
+		t.Errorf("descriptor id = %s, want vid1", descriptor.Id)
+	}
+
+	t.Run("range", func(t *testing.T) {
+		response, body := get("/v1/stream?videoId=vid1&itag=18", http.Header{"Range": {"bytes=100-199"}})
+		if response.StatusCode != http.StatusPartialContent {
+			t.Fatalf("stream = %d %s, want 206", response.StatusCode, body)
+		}
+		if contentRange := response.Header.Get("Content-Range"); contentRange != "bytes 100-199/1000" {
+			t.Errorf("Content-Range = %s, want bytes 100-199/1000", contentRange)
+		}
+		if !bytes.Equal(body, media[100:200]) {
+			t.Errorf("streamed %d bytes not matching the range", len(body))
+		}
+
+		response, body = get("/v1/stream?videoId=vid1&itag=18", nil)
+		if response.StatusCode != http.StatusOK || !bytes.Equal(body, media) {
+			t.Errorf("stream = %d with %d bytes, want 200 with the whole media", response.StatusCode, len(body))
+		}
+	})
+
+	t.Run("expired media URL", func(t *testing.T) {
+		before := atomic.LoadInt32(playerRequests)
+		// The media URLs resolved so far are refused from now on.
+		clock.Advance(2 * fakeExpiresInSeconds * time.Second)
+
+		response, body := get("/v1/stream?videoId=vid1&itag=18", http.Header{"Range": {"bytes=0-9"}})
+		if response.StatusCode != http.StatusPartialContent || !bytes.Equal(body, media[:10]) {
+			t.Fatalf("stream = %d %s, want 206 after refreshing the media URL", response.StatusCode, body)
+		}
+		if got := atomic.LoadInt32(playerRequests) - before; got != 1 {
+			t.Errorf("resolved %d times, want 1 refresh", got)
+		}
+	})
+
+	t.Run("forbidden media", func(t *testing.T) {
+		before := atomic.LoadInt32(playerRequests)
+		response, body := get("/v1/stream?videoId=gone&itag=18", nil)
+		if response.StatusCode != http.StatusBadGateway {
+			t.Errorf("stream = %d %s, want 502", response.StatusCode, body)
+		}
+		if got := atomic.LoadInt32(playerRequests) - before; got != 2 {
+			t.Errorf("resolved %d times, want 2 before giving up", got)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			path string
+			want int
+		}{
+			{"/v1/resolve?videoId=limited", http.StatusBadGateway},
+			{"/v1/resolve?videoId=missing", http.StatusUnprocessableEntity},
+			{"/v1/stream?videoId=missing&itag=18", http.StatusUnprocessableEntity},
+			{"/v1/resolve?videoId=..", http.StatusUnprocessableEntity},
+			{"/v1/stream?videoId=vid1&itag=22", http.StatusNotFound},
+		}
+		for _, test := range tests {
+			if response, body := get(test.path, nil); response.StatusCode != test.want {
+				t.Errorf("%s = %d %s, want %d", test.path, response.StatusCode, body, test.want)
+			}
+		}
+	})
+}
@@ -0,0 +1,458 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"web-helper/internal/pb"
+)
+
+// defaultLiveWatchInterval is how often LiveWatch checks the live status unless the
+// caller asks otherwise, minLiveWatchInterval bounds the upstream load of a watch.
+const (
+	defaultLiveWatchInterval = 30 * time.Second
+	minLiveWatchInterval     = 5 * time.Second
+)
+
+// captionFormats are the formats the timedtext endpoint serves the caption tracks in.
+var captionFormats = map[string]bool{"vtt": true, "ttml": true, "srv1": true, "srv2": true, "srv3": true, "json3": true}
+
+// grpcService implements the WebHelper gRPC API, see proto/webhelper/v1/webhelper.proto.
+type grpcService struct {
+	pb.UnimplementedWebHelperServer
+
+	server *Server
+	client *Client
+	auth   *Authenticator
+}
+
+// NewGRPCServer returns a gRPC server of the WebHelper API alongside the HTTP server. It
+// resolves the videos with the client of the server, builds the descriptors like it, media
+// proxying and stream tokens included, and authorizes the callers with its Auth.
+func NewGRPCServer(server *Server) (*grpc.Server, error) {
+	if server.ProxyMedia && server.PublicURL == "" {
+		return nil, errors.New("the gRPC API needs the public URL of the server to proxy the media")
+	}
+
+	service := &grpcService{server: server, client: server.client, auth: server.Auth}
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(service.interceptUnary),
+		grpc.StreamInterceptor(service.interceptStream),
+	)
+	pb.RegisterWebHelperServer(grpcServer, service)
+	return grpcServer, nil
+}
+
+func (s *grpcService) Resolve(ctx context.Context, request *pb.ResolveRequest) (*pb.Descriptor, error) {
+	if request.VideoId == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing video_id")
+	}
+	selector, err := ParseSelector(request.Select)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	response, err := s.client.GetPlayerResponse(ctx, request.VideoId)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if err := response.PlayabilityStatus.Err(); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return descriptorProto(s.server.newDescriptor(ctx, s.server.PublicURL, request.VideoId, response, selector)), nil
+}
+
+func (s *grpcService) Search(ctx context.Context, request *pb.SearchRequest) (*pb.SearchResponse, error) {
+	if request.Query == "" && request.Continuation == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing query")
+	}
+
+	body, err := s.client.Search(ctx, SearchRequest{
+		Query:        request.Query,
+		Params:       request.Params,
+		Continuation: request.Continuation,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	response, err := structpb.NewStruct(fields)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.SearchResponse{Response: response}, nil
+}
+
+func (s *grpcService) Captions(ctx context.Context, request *pb.CaptionsRequest) (*pb.CaptionsResponse, error) {
+	if request.VideoId == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing video_id")
+	}
+	if request.Format != "" && !captionFormats[request.Format] {
+		return nil, status.Error(codes.InvalidArgument, "unknown caption format: "+request.Format)
+	}
+
+	response, err := s.client.GetPlayerResponse(ctx, request.VideoId)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if err := response.PlayabilityStatus.Err(); err != nil {
+		return nil, grpcError(err)
+	}
+
+	var track *CaptionTrack
+	for i, candidate := range response.Captions.PlayerCaptionsTracklistRenderer.CaptionTracks {
+		if request.Language == "" || candidate.LanguageCode == request.Language {
+			track = &response.Captions.PlayerCaptionsTracklistRenderer.CaptionTracks[i]
+			break
+		}
+	}
+	if track == nil && request.Language != "" {
+		return nil, status.Error(codes.NotFound, "no caption track in "+request.Language)
+	}
+	if track == nil {
+		return nil, status.Error(codes.NotFound, "no caption track")
+	}
+
+	trackURL, err := url.Parse(track.BaseUrl)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if request.Format != "" {
+		query := trackURL.Query()
+		query.Set("fmt", request.Format)
+		trackURL.RawQuery = query.Encode()
+	}
+
+	// The track URL is signed like the media URLs and expires with them.
+	content, err := s.client.FetchArtifact(ctx, request.VideoId, trackURL.String(), playerResponseTTL(response))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.CaptionsResponse{
+		Caption: &pb.Caption{
+			Language:  track.LanguageCode,
+			Name:      track.Name.String(),
+			Automatic: track.Kind == "asr",
+			Url:       trackURL.String(),
+		},
+		Content: content,
+	}, nil
+}
+
+func (s *grpcService) LiveWatch(request *pb.LiveWatchRequest, stream pb.WebHelper_LiveWatchServer) error {
+	if request.VideoId == "" {
+		return status.Error(codes.InvalidArgument, "missing video_id")
+	}
+	interval := defaultLiveWatchInterval
+	if request.IntervalSeconds > 0 {
+		interval = time.Duration(request.IntervalSeconds) * time.Second
+	}
+	if interval < minLiveWatchInterval {
+		interval = minLiveWatchInterval
+	}
+
+	ctx := stream.Context()
+	var last *pb.LiveStatus
+	for {
+		response, err := s.client.GetPlayerResponse(ctx, request.VideoId)
+		if err != nil {
+			return grpcError(err)
+		}
+		// An upcoming stream is offline until it starts.
+		if playability := response.PlayabilityStatus; playability.Status != "LIVE_STREAM_OFFLINE" && playability.Err() != nil {
+			return grpcError(playability.Err())
+		}
+
+		current := liveStatusProto(request.VideoId, response)
+		if last == nil || !proto.Equal(last, current) {
+			if err := stream.Send(current); err != nil {
+				return err
+			}
+		}
+		if !current.Live.IsLive && !current.Live.IsUpcoming {
+			return nil
+		}
+		last = current
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return status.FromContextError(ctx.Err()).Err()
+		case <-timer.C:
+		}
+
+		// The cached player response would hide the changes until its media URLs expire.
+		if err := s.client.InvalidatePlayerResponse(ctx, request.VideoId); err != nil {
+			s.client.log(ctx, LevelWarn, "failed to invalidate the player response", "videoId", request.VideoId, "error", err)
+		}
+	}
+}
+
+// grpcError returns the gRPC status error of a failed call. Unplayable videos get the code
+// of their playability, and upstream failures UNAVAILABLE like the 502 of the HTTP API.
+func grpcError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var playabilityErr *PlayabilityError
+	var driftErr *SchemaDriftError
+	var limitErr *LimitError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case errors.As(err, &playabilityErr):
+		return status.Error(playabilityCode(playabilityErr.Status), err.Error())
+	case errors.As(err, &driftErr):
+		return status.Error(codes.Internal, err.Error())
+	case errors.As(err, &limitErr):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return status.Error(codes.Unavailable, err.Error())
+}
+
+func playabilityCode(playability string) codes.Code {
+	switch playability {
+	case "ERROR":
+		return codes.NotFound
+	case "LOGIN_REQUIRED", "AGE_CHECK_REQUIRED", "CONTENT_CHECK_REQUIRED":
+		return codes.PermissionDenied
+	case "UNPLAYABLE":
+		return codes.FailedPrecondition
+	case "LIVE_STREAM_OFFLINE":
+		return codes.Unavailable
+	}
+	return codes.Unknown
+}
+
+func descriptorProto(d *Descriptor) *pb.Descriptor {
+	descriptor := &pb.Descriptor{
+		Version:         int32(d.Version),
+		Id:              d.Id,
+		Title:           d.Title,
+		Author:          d.Author,
+		DurationSeconds: int32(d.DurationSeconds),
+		Playability:     d.Playability,
+		Streams: &pb.Streams{
+			Video: streamProto(d.Streams.Video),
+			Audio: streamProto(d.Streams.Audio),
+			Muxed: streamProto(d.Streams.Muxed),
+		},
+		Live: liveProto(d.Live),
+	}
+	for _, thumbnail := range d.Thumbnails {
+		descriptor.Thumbnails = append(descriptor.Thumbnails, &pb.Thumbnail{
+			Url:    thumbnail.Url,
+			Width:  int32(thumbnail.Width),
+			Height: int32(thumbnail.Height),
+		})
+	}
+	for _, caption := range d.Captions {
+		descriptor.Captions = append(descriptor.Captions, &pb.Caption{
+			Language:  caption.Language,
+			Name:      caption.Name,
+			Automatic: caption.Automatic,
+			Url:       caption.URL,
+		})
+	}
+	if d.ExpiresAt != nil {
+		descriptor.ExpiresAt = timestamppb.New(*d.ExpiresAt)
+	}
+	return descriptor
+}
+
+func streamProto(s *DescriptorStream) *pb.Stream {
+	if s == nil {
+		return nil
+	}
+	stream := &pb.Stream{
+		Itag:          int32(s.Itag),
+		Url:           s.URL,
+		MimeType:      s.MimeType,
+		Bitrate:       int32(s.Bitrate),
+		ContentLength: s.ContentLength,
+		Width:         int32(s.Width),
+		Height:        int32(s.Height),
+		Fps:           int32(s.FPS),
+		DynamicRange:  string(s.DynamicRange),
+		AudioLayout:   string(s.AudioLayout),
+		AudioChannels: int32(s.AudioChannels),
+		SampleRate:    int32(s.SampleRate),
+		Language:      s.Language,
+	}
+	if s.ToneMapping != nil {
+		stream.ToneMapping = &pb.ToneMapping{
+			Transfer:  s.ToneMapping.Transfer,
+			Primaries: s.ToneMapping.Primaries,
+			Matrix:    s.ToneMapping.Matrix,
+		}
+	}
+	return stream
+}
+
+func liveProto(l DescriptorLive) *pb.Live {
+	return &pb.Live{
+		IsLive:          l.IsLive,
+		IsUpcoming:      l.IsUpcoming,
+		WasLive:         l.WasLive,
+		HlsManifestUrl:  l.HlsManifestUrl,
+		DashManifestUrl: l.DashManifestUrl,
+	}
+}
+
+func liveStatusProto(videoID string, response *PlayerResponse) *pb.LiveStatus {
+	details := response.VideoDetails
+	return &pb.LiveStatus{
+		VideoId:     videoID,
+		Playability: response.PlayabilityStatus.Status,
+		Reason:      response.PlayabilityStatus.Reason,
+		Live: liveProto(DescriptorLive{
+			IsLive:          details.IsLive,
+			IsUpcoming:      details.IsUpcoming,
+			WasLive:         details.IsLiveContent && !details.IsLive && !details.IsUpcoming,
+			HlsManifestUrl:  response.StreamingData.HlsManifestUrl,
+			DashManifestUrl: response.StreamingData.DashManifestUrl,
+		}),
+	}
+}
+
+func (s *grpcService) interceptUnary(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (response interface{}, err error) {
+	ctx, finish := s.begin(ctx, info.FullMethod)
+	defer func() { finish(err) }()
+
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, request)
+}
+
+func (s *grpcService) interceptStream(server interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	ctx, finish := s.begin(stream.Context(), info.FullMethod)
+	defer func() { finish(err) }()
+
+	if err := s.authorize(ctx); err != nil {
+		return err
+	}
+	return handler(server, &contextServerStream{ServerStream: stream, ctx: ctx})
+}
+
+// begin starts the span, request ID and metrics of a call, finish ends them.
+func (s *grpcService) begin(ctx context.Context, method string) (context.Context, func(error)) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	requestID := ""
+	if values := md.Get("x-request-id"); len(values) > 0 && len(values[0]) <= 128 {
+		requestID = values[0]
+	}
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs("x-request-id", requestID))
+
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	ctx, span := tracer.Start(ContextWithRequestID(ctx, requestID), method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("rpc.system", "grpc"), attribute.String("rpc.method", method)))
+
+	start := time.Now()
+	return ctx, func(err error) {
+		code := status.Code(err)
+		grpcDuration.ObserveSince(start, method)
+		grpcRequests.Inc(method, code.String())
+		span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+		if err != nil {
+			span.SetStatus(otelcodes.Error, err.Error())
+		}
+		span.End()
+		s.client.log(ctx, LevelInfo, "rpc served", "method", method, "code", code, "duration", time.Since(start))
+	}
+}
+
+// authorize authenticates the caller and enforces its limits, the same credentials are
+// accepted as metadata as the headers of the HTTP API.
+func (s *grpcService) authorize(ctx context.Context) error {
+	if s.auth == nil {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	header := http.Header{}
+	for key, values := range md {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+
+	now := time.Now()
+	caller, limits, err := s.auth.authenticate(header, now)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err := s.auth.admit(caller, limits, now); err != nil {
+		var limitErr *LimitError
+		if errors.As(err, &limitErr) {
+			_ = grpc.SetTrailer(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(math.Ceil(limitErr.RetryAfter.Seconds())))))
+			return status.Error(codes.ResourceExhausted, err.Error())
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// contextServerStream replaces the context of the stream with one carrying the span and
+// request ID of the call.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// metadataCarrier reads the W3C trace context of the call from its metadata.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key string, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// InnertubeClient identifies the client and locale of an innertube request.
+type InnertubeClient struct {
+	ClientName        string `json:"clientName"`
+	ClientVersion     string `json:"clientVersion"`
+	AndroidSdkVersion int    `json:"androidSdkVersion,omitempty"`
+	Hl                string `json:"hl"`
+	Gl                string `json:"gl"`
+	UtcOffsetMinutes  int    `json:"utcOffsetMinutes"`
+}
+
+// InnertubeContext is sent with every innertube request.
+type InnertubeContext struct {
+	Client InnertubeClient `json:"client"`
+}
+
+type PlayerRequest struct {
+	Context InnertubeContext `json:"context"`
+	VideoId string           `json:"videoId"`
+}
+
+type NextRequest struct {
+	Context      InnertubeContext `json:"context"`
+	VideoId      string           `json:"videoId,omitempty"`
+	PlaylistId   string           `json:"playlistId,omitempty"`
+	Continuation string           `json:"continuation,omitempty"`
+}
+
+type BrowseRequest struct {
+	Context      InnertubeContext `json:"context"`
+	BrowseId     string           `json:"browseId,omitempty"`
+	Params       string           `json:"params,omitempty"`
+	Continuation string           `json:"continuation,omitempty"`
+}
+
+type SearchRequest struct {
+	Context      InnertubeContext `json:"context"`
+	Query        string           `json:"query,omitempty"`
+	Params       string           `json:"params,omitempty"`
+	Continuation string           `json:"continuation,omitempty"`
+}
+
+// NewContext returns the innertube context for the client profile and locale.
+func (c *Client) NewContext() InnertubeContext {
+	return InnertubeContext{
+		Client: InnertubeClient{
+			ClientName:        c.Profile.Name,
+			ClientVersion:     c.Profile.Version,
+			AndroidSdkVersion: c.Profile.AndroidSdkVersion,
+			Hl:                c.Language,
+			Gl:                c.Region,
+			UtcOffsetMinutes:  c.UTCOffsetMinutes,
+		},
+	}
+}
+
+// call marshals the request, posts it to the endpoint and passes the response body to read.
+// Innertube requests only read data, so they are idempotent and retried per the policy.
+func (c *Client) call(ctx context.Context, endpoint string, request interface{}, read func(body io.Reader) error) error {
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to build %s request: %v", endpoint, err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		if c.Breaker != nil {
+			if err := c.Breaker.Allow(); err != nil {
+				return err
+			}
+		}
+
+		err = c.post(ctx, endpoint, requestBody, read)
+		if c.Breaker != nil {
+			c.Breaker.Record(err)
+		}
+		if err == nil {
+			return nil
+		}
+
+		delay, retry := c.Retry.retryDelay(ctx, attempt, err)
+		if !retry {
+			return err
+		}
+
+		c.log(ctx, LevelInfo, "retrying innertube request", "endpoint", endpoint, "attempt", attempt, "delay", delay, "error", err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// callRaw calls the endpoint and returns the raw response body.
+func (c *Client) callRaw(ctx context.Context, endpoint string, request interface{}) (json.RawMessage, error) {
+	var responseBody []byte
+	if err := c.call(ctx, endpoint, request, readAll(&responseBody)); err != nil {
+		return nil, err
+	}
+
+	if c.Sessions != nil {
+		var envelope struct {
+			ResponseContext ResponseContext `json:"responseContext"`
+		}
+		if json.Unmarshal(responseBody, &envelope) == nil {
+			c.updateSession(ctx, envelope.ResponseContext.VisitorData, nil)
+		}
+	}
+	return responseBody, nil
+}
+
+// Next requests the watch next data of a video or playlist.
+func (c *Client) Next(ctx context.Context, request NextRequest) (json.RawMessage, error) {
+	request.Context = c.NewContext()
+	return c.callRaw(ctx, "next", request)
+}
+
+// Browse requests a browse page such as a channel or a playlist.
+func (c *Client) Browse(ctx context.Context, request BrowseRequest) (json.RawMessage, error) {
+	request.Context = c.NewContext()
+	return c.callRaw(ctx, "browse", request)
+}
+
+// Search requests the search results of the query.
+func (c *Client) Search(ctx context.Context, request SearchRequest) (json.RawMessage, error) {
+	request.Context = c.NewContext()
+	return c.callRaw(ctx, "search", request)
+}
@@ -0,0 +1,224 @@
+package internal
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// jwtLeeway tolerates that much clock skew with the issuer.
+const jwtLeeway = time.Minute
+
+// JWTClaims are the registered claims checked by the JWTVerifier.
+type JWTClaims struct {
+	Subject   string      `json:"sub"`
+	Issuer    string      `json:"iss"`
+	Audience  jwtAudience `json:"aud"`
+	ExpiresAt int64       `json:"exp"`
+	NotBefore int64       `json:"nbf"`
+}
+
+// jwtAudience is the aud claim, either a string or an array of strings.
+type jwtAudience []string
+
+func (a *jwtAudience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = jwtAudience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+// JWTVerifier verifies HS256/384/512 and RS256/384/512 signed JWTs.
+type JWTVerifier struct {
+	// Secret verifies the HS tokens without a kid, or with a kid not in Keys.
+	Secret []byte
+	// Keys are the keys by kid, []byte for HS and *rsa.PublicKey for RS tokens.
+	Keys map[string]interface{}
+	// Issuer, if set, must match the iss claim.
+	Issuer string
+	// Audience, if set, must be in the aud claim.
+	Audience string
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	K   string `json:"k"`
+}
+
+// LoadJWKS reads the RSA and oct keys of the JWK set file into the verifier keys.
+func (v *JWTVerifier) LoadJWKS(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &set); err != nil {
+		return fmt.Errorf("failed to parse JWKS %s: %v", path, err)
+	}
+
+	if v.Keys == nil {
+		v.Keys = map[string]interface{}{}
+	}
+	for _, key := range set.Keys {
+		switch key.Kty {
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(key.N)
+			e, errE := base64.RawURLEncoding.DecodeString(key.E)
+			exponent := new(big.Int).SetBytes(e)
+			if errN != nil || errE != nil || len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > math.MaxInt32 {
+				return fmt.Errorf("invalid RSA key %s in JWKS %s", key.Kid, path)
+			}
+			v.Keys[key.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}
+		case "oct":
+			// An empty secret would let anyone sign tokens of the kid.
+			secret, err := base64.RawURLEncoding.DecodeString(key.K)
+			if err != nil || len(secret) == 0 {
+				return fmt.Errorf("invalid oct key %s in JWKS %s", key.Kid, path)
+			}
+			v.Keys[key.Kid] = secret
+		}
+	}
+	return nil
+}
+
+var errInvalidJWT = errors.New("invalid JWT")
+
+// Verify checks the signature and the claims of the token.
+func (v *JWTVerifier) Verify(token string, now time.Time) (*JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errInvalidJWT
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, errInvalidJWT
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errInvalidJWT
+	}
+
+	var key interface{}
+	if k, found := v.Keys[header.Kid]; found {
+		key = k
+	} else if len(v.Secret) > 0 {
+		key = v.Secret
+	}
+	if err := verifyJWTSignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	claims := &JWTClaims{}
+	if err := decodeJWTPart(parts[1], claims); err != nil {
+		return nil, errInvalidJWT
+	}
+	if claims.ExpiresAt == 0 || now.After(time.Unix(claims.ExpiresAt, 0).Add(jwtLeeway)) {
+		return nil, errors.New("JWT expired")
+	}
+	if claims.NotBefore != 0 && now.Add(jwtLeeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, errors.New("JWT not valid yet")
+	}
+	if v.Issuer != "" && claims.Issuer != v.Issuer {
+		return nil, errors.New("JWT issuer not accepted")
+	}
+	if v.Audience != "" && !claims.Audience.contains(v.Audience) {
+		return nil, errors.New("JWT audience not accepted")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("JWT has no subject")
+	}
+	return claims, nil
+}
+
+func (a jwtAudience) contains(audience string) bool {
+	for _, value := range a {
+		if value == audience {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeJWTPart(part string, target interface{}) error {
+	content, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, target)
+}
+
+// verifyJWTSignature checks the signature with the key, which must be of the type of the
+// algorithm so an RSA public key can't be used as an HMAC secret.
+func verifyJWTSignature(alg string, key interface{}, signed string, signature []byte) error {
+	if len(alg) != 5 {
+		return fmt.Errorf("unsupported JWT algorithm: %s", alg)
+	}
+
+	var newHash func() hash.Hash
+	var cryptoHash crypto.Hash
+	switch alg[2:] {
+	case "256":
+		newHash, cryptoHash = sha256.New, crypto.SHA256
+	case "384":
+		newHash, cryptoHash = sha512.New384, crypto.SHA384
+	case "512":
+		newHash, cryptoHash = sha512.New, crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported JWT algorithm: %s", alg)
+	}
+
+	switch alg[:2] {
+	case "HS":
+		secret, ok := key.([]byte)
+		if !ok {
+			return errInvalidJWT
+		}
+		mac := hmac.New(newHash, secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errInvalidJWT
+		}
+		return nil
+	case "RS":
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errInvalidJWT
+		}
+		digest := newHash()
+		digest.Write([]byte(signed))
+		if rsa.VerifyPKCS1v15(publicKey, cryptoHash, digest.Sum(nil), signature) != nil {
+			return errInvalidJWT
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported JWT algorithm: %s", alg)
+}
@@ -0,0 +1,201 @@
+package internal
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// signJWT returns the token of the header and the claims, signed by the alg with the key:
+// []byte for HS256, *rsa.PrivateKey for RS256.
+func signJWT(t *testing.T, header, claims map[string]interface{}, key interface{}) string {
+	t.Helper()
+	encode := func(value interface{}) string {
+		serialized, err := json.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(serialized)
+	}
+	signed := encode(header) + "." + encode(claims)
+
+	var signature []byte
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func writeJWKS(t *testing.T, keys ...map[string]string) string {
+	t.Helper()
+	serialized, err := json.Marshal(map[string]interface{}{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, serialized, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kid": kid,
+		"kty": "RSA",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func TestLoadJWKS(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := rsaJWK("rsa", &privateKey.PublicKey)
+	secret := base64.RawURLEncoding.EncodeToString([]byte("secret"))
+
+	tests := []struct {
+		name    string
+		keys    []map[string]string
+		wantErr bool
+	}{
+		{name: "valid", keys: []map[string]string{valid, {"kid": "hs", "kty": "oct", "k": secret}}},
+		{name: "unsupported key type ignored", keys: []map[string]string{{"kid": "ec", "kty": "EC", "x": "AA", "y": "AA"}}},
+		{name: "empty oct secret", keys: []map[string]string{{"kid": "hs", "kty": "oct", "k": ""}}, wantErr: true},
+		{name: "missing oct secret", keys: []map[string]string{{"kid": "hs", "kty": "oct"}}, wantErr: true},
+		{name: "invalid oct secret", keys: []map[string]string{{"kid": "hs", "kty": "oct", "k": "!"}}, wantErr: true},
+		{name: "empty modulus", keys: []map[string]string{{"kid": "rsa", "kty": "RSA", "n": "", "e": valid["e"]}}, wantErr: true},
+		{name: "missing exponent", keys: []map[string]string{{"kid": "rsa", "kty": "RSA", "n": valid["n"]}}, wantErr: true},
+		{name: "exponent 1", keys: []map[string]string{{"kid": "rsa", "kty": "RSA", "n": valid["n"], "e": "AQ"}}, wantErr: true},
+		{name: "huge exponent", keys: []map[string]string{{"kid": "rsa", "kty": "RSA", "n": valid["n"], "e": "AQAAAAAAAAAAAQ"}}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			verifier := &JWTVerifier{}
+			err := verifier.LoadJWKS(writeJWKS(t, test.keys...))
+			if (err != nil) != test.wantErr {
+				t.Errorf("LoadJWKS error = %v, want error %t", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestJWTVerify(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hsSecret := []byte("kid-secret")
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER})
+
+	verifier := &JWTVerifier{Secret: []byte("default-secret"), Issuer: "https://issuer.example.com", Audience: "web-helper"}
+	err = verifier.LoadJWKS(writeJWKS(t, rsaJWK("rsa", &privateKey.PublicKey),
+		map[string]string{"kid": "hs", "kty": "oct", "k": base64.RawURLEncoding.EncodeToString(hsSecret)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := func(change func(claims map[string]interface{})) map[string]interface{} {
+		claims := map[string]interface{}{
+			"sub": "user-1",
+			"iss": "https://issuer.example.com",
+			"aud": "web-helper",
+			"exp": now.Add(time.Hour).Unix(),
+		}
+		if change != nil {
+			change(claims)
+		}
+		return claims
+	}
+	rs256 := map[string]interface{}{"alg": "RS256", "kid": "rsa"}
+	hs256 := map[string]interface{}{"alg": "HS256", "kid": "hs"}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "RS256", token: signJWT(t, rs256, claims(nil), privateKey)},
+		{name: "HS256 by kid", token: signJWT(t, hs256, claims(nil), hsSecret)},
+		{name: "HS256 without kid", token: signJWT(t, map[string]interface{}{"alg": "HS256"}, claims(nil), []byte("default-secret"))},
+		{name: "audience array", token: signJWT(t, rs256, claims(func(c map[string]interface{}) { c["aud"] = []string{"other", "web-helper"} }), privateKey)},
+		{name: "expired within leeway", token: signJWT(t, rs256, claims(func(c map[string]interface{}) { c["exp"] = now.Add(-30 * time.Second).Unix() }), privateKey)},
+
+		{name: "HS256 signed with the RSA public key", token: signJWT(t, map[string]interface{}{"alg": "HS256", "kid": "rsa"}, claims(nil), publicKeyPEM), wantErr: true},
+		{name: "HS256 signed with the RSA modulus", token: signJWT(t, map[string]interface{}{"alg": "HS256", "kid": "rsa"}, claims(nil), privateKey.PublicKey.N.Bytes()), wantErr: true},
+		{name: "RS256 against an HS kid", token: signJWT(t, map[string]interface{}{"alg": "RS256", "kid": "hs"}, claims(nil), privateKey), wantErr: true},
+		{name: "RS256 without kid", token: signJWT(t, map[string]interface{}{"alg": "RS256"}, claims(nil), privateKey), wantErr: true},
+		{name: "alg none", token: signJWT(t, map[string]interface{}{"alg": "none", "kid": "hs"}, claims(nil), []byte(nil)), wantErr: true},
+		{name: "other RSA key", token: signJWT(t, rs256, claims(nil), otherKey), wantErr: true},
+		{name: "wrong secret", token: signJWT(t, hs256, claims(nil), []byte("guess")), wantErr: true},
+		{name: "expired", token: signJWT(t, rs256, claims(func(c map[string]interface{}) { c["exp"] = now.Add(-2 * time.Minute).Unix() }), privateKey), wantErr: true},
+		{name: "no expiry", token: signJWT(t, rs256, claims(func(c map[string]interface{}) { delete(c, "exp") }), privateKey), wantErr: true},
+		{name: "not valid yet", token: signJWT(t, rs256, claims(func(c map[string]interface{}) { c["nbf"] = now.Add(2 * time.Minute).Unix() }), privateKey), wantErr: true},
+		{name: "valid soon within leeway", token: signJWT(t, rs256, claims(func(c map[string]interface{}) { c["nbf"] = now.Add(30 * time.Second).Unix() }), privateKey)},
+		{name: "other audience", token: signJWT(t, rs256, claims(func(c map[string]interface{}) { c["aud"] = "other" }), privateKey), wantErr: true},
+		{name: "no audience", token: signJWT(t, rs256, claims(func(c map[string]interface{}) { delete(c, "aud") }), privateKey), wantErr: true},
+		{name: "other issuer", token: signJWT(t, rs256, claims(func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" }), privateKey), wantErr: true},
+		{name: "no subject", token: signJWT(t, rs256, claims(func(c map[string]interface{}) { delete(c, "sub") }), privateKey), wantErr: true},
+		{name: "malformed", token: "a.b", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claims, err := verifier.Verify(test.token, now)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("Verify accepted the token of %s", claims.Subject)
+				}
+				return
+			}
+			if err != nil || claims.Subject != "user-1" {
+				t.Errorf("Verify = %+v, %v, want the claims of user-1", claims, err)
+			}
+		})
+	}
+}
+
+func TestJWTVerifyEmptySecret(t *testing.T) {
+	now := time.Now()
+	token := signJWT(t, map[string]interface{}{"alg": "HS256"},
+		map[string]interface{}{"sub": "user-1", "exp": now.Add(time.Hour).Unix()}, []byte{})
+
+	for _, verifier := range []*JWTVerifier{{}, {Secret: []byte{}}} {
+		if _, err := verifier.Verify(token, now); err == nil {
+			t.Errorf("a verifier with the secret %q accepted a token signed with an empty secret", verifier.Secret)
+		}
+	}
+}
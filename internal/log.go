@@ -0,0 +1,200 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Level is the severity of a log entry.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return strconv.Itoa(int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel parses debug, info, warn or error.
+func ParseLevel(value string) (Level, error) {
+	for level, name := range levelNames {
+		if strings.EqualFold(value, name) {
+			return Level(level), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level: %s", value)
+}
+
+// StructuredLogger writes leveled entries of key value pairs as logfmt text or JSON
+// lines. The request ID and the trace of the context are added to every entry.
+type StructuredLogger struct {
+	// Level is the lowest level written.
+	Level Level
+	// JSON writes JSON lines instead of logfmt text.
+	JSON bool
+
+	mu     *sync.Mutex
+	out    io.Writer
+	fields []interface{}
+}
+
+// NewStructuredLogger returns a logger writing to out in the text or json format.
+func NewStructuredLogger(out io.Writer, level Level, format string) (*StructuredLogger, error) {
+	if format != "text" && format != "json" {
+		return nil, fmt.Errorf("unknown log format: %s", format)
+	}
+	return &StructuredLogger{Level: level, JSON: format == "json", mu: &sync.Mutex{}, out: out}, nil
+}
+
+// With returns a logger adding the key value pairs to every entry.
+func (l *StructuredLogger) With(keyvals ...interface{}) *StructuredLogger {
+	logger := *l
+	logger.fields = append(append([]interface{}(nil), l.fields...), keyvals...)
+	return &logger
+}
+
+// Printf writes an info entry, so the logger can be the Logger of a Client.
+func (l *StructuredLogger) Printf(format string, v ...interface{}) {
+	l.Log(context.Background(), LevelInfo, fmt.Sprintf(format, v...))
+}
+
+// Log writes the entry if its level is enabled.
+func (l *StructuredLogger) Log(ctx context.Context, level Level, msg string, keyvals ...interface{}) {
+	if level < l.Level {
+		return
+	}
+
+	fields := []interface{}{"time", time.Now().UTC().Format(time.RFC3339Nano), "level", level.String(), "msg", msg}
+	if requestID := RequestID(ctx); requestID != "" {
+		fields = append(fields, "request_id", requestID)
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		fields = append(fields, "trace_id", spanContext.TraceID().String(), "span_id", spanContext.SpanID().String())
+	}
+	fields = append(fields, l.fields...)
+	fields = append(fields, keyvals...)
+
+	var line []byte
+	if l.JSON {
+		line = formatJSONLine(fields)
+	} else {
+		line = []byte(formatLogfmt(fields) + "\n")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = l.out.Write(line)
+}
+
+// logValue returns the value as logged, errors and durations by their text.
+func logValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case error:
+		return value.Error()
+	case time.Duration:
+		return value.String()
+	case fmt.Stringer:
+		return value.String()
+	}
+	return value
+}
+
+func formatLogfmt(fields []interface{}) string {
+	var builder strings.Builder
+	for i := 0; i < len(fields); i += 2 {
+		if i > 0 {
+			builder.WriteByte(' ')
+		}
+		builder.WriteString(fmt.Sprint(fields[i]))
+		builder.WriteByte('=')
+
+		var value string
+		if i+1 < len(fields) {
+			value = fmt.Sprint(logValue(fields[i+1]))
+		}
+		if value == "" || strings.ContainsAny(value, " \"=\t\n") {
+			value = strconv.Quote(value)
+		}
+		builder.WriteString(value)
+	}
+	return builder.String()
+}
+
+// formatJSONLine writes the fields as a JSON object keeping their order.
+func formatJSONLine(fields []interface{}) []byte {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i := 0; i < len(fields); i += 2 {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		key, _ := json.Marshal(fmt.Sprint(fields[i]))
+		buffer.Write(key)
+		buffer.WriteByte(':')
+
+		var value interface{}
+		if i+1 < len(fields) {
+			value = logValue(fields[i+1])
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			encoded, _ = json.Marshal(fmt.Sprint(value))
+		}
+		buffer.Write(encoded)
+	}
+	buffer.WriteString("}\n")
+	return buffer.Bytes()
+}
+
+type requestIDKey struct{}
+
+// ContextWithRequestID tags the logs of the requests made with the context.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID of the context, empty if none.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// newRequestID returns a random request ID.
+func newRequestID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// log writes an entry with the client logger: structured if it is a StructuredLogger,
+// formatted as logfmt for any other Logger.
+func (c *Client) log(ctx context.Context, level Level, msg string, keyvals ...interface{}) {
+	switch logger := c.Logger.(type) {
+	case nil:
+	case *StructuredLogger:
+		logger.Log(ctx, level, msg, keyvals...)
+	default:
+		fields := append([]interface{}{"level", level.String(), "msg", msg}, keyvals...)
+		logger.Printf("%s", formatLogfmt(fields))
+	}
+}
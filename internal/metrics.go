@@ -0,0 +1,233 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// metricsDurationBuckets are the upper bounds in seconds of the latency histograms.
+var metricsDurationBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// metric is a family of samples written in the Prometheus text format.
+type metric interface {
+	write(w io.Writer)
+}
+
+// MetricsRegistry holds the metrics exposed in the Prometheus text format.
+type MetricsRegistry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+func (r *MetricsRegistry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+func (r *MetricsRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	r.mu.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.Unlock()
+
+	for _, m := range metrics {
+		m.write(w)
+	}
+}
+
+// labelKey joins the label values into a map key, \xff can't appear in valid UTF-8.
+func labelKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+func formatLabels(names []string, values []string, extra ...string) string {
+	pairs := make([]string, 0, len(names)+1)
+	for i, name := range names {
+		pairs = append(pairs, name+"="+strconv.Quote(values[i]))
+	}
+	if len(extra) == 2 {
+		pairs = append(pairs, extra[0]+"="+strconv.Quote(extra[1]))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// CounterVec is a counter partitioned by labels.
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]float64
+	keys   map[string][]string
+}
+
+func newCounterVec(registry *MetricsRegistry, name string, help string, labels ...string) *CounterVec {
+	counter := &CounterVec{name: name, help: help, labels: labels, values: map[string]float64{}, keys: map[string][]string{}}
+	registry.register(counter)
+	return counter
+}
+
+// Add adds the delta to the counter of the label values.
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	key := labelKey(labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, found := c.keys[key]; !found {
+		c.keys[key] = labelValues
+	}
+	c.values[key] += delta
+}
+
+// Inc adds one to the counter of the label values.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	if len(c.labels) == 0 && len(c.values) == 0 {
+		fmt.Fprintf(w, "%s 0\n", c.name)
+	}
+	for _, key := range sortedKeys(c.keys) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, c.keys[key]), formatFloat(c.values[key]))
+	}
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// HistogramVec is a histogram partitioned by labels.
+type HistogramVec struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+
+	mu         sync.Mutex
+	histograms map[string]*histogram
+	keys       map[string][]string
+}
+
+func newHistogramVec(registry *MetricsRegistry, name string, help string, buckets []float64, labels ...string) *HistogramVec {
+	vec := &HistogramVec{
+		name:       name,
+		help:       help,
+		labels:     labels,
+		buckets:    buckets,
+		histograms: map[string]*histogram{},
+		keys:       map[string][]string{},
+	}
+	registry.register(vec)
+	return vec
+}
+
+// Observe records the value in the histogram of the label values.
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	key := labelKey(labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hist, found := h.histograms[key]
+	if !found {
+		hist = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.histograms[key] = hist
+		h.keys[key] = labelValues
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			hist.counts[i]++
+		}
+	}
+	hist.count++
+	hist.sum += value
+}
+
+// ObserveSince records the seconds elapsed since the start.
+func (h *HistogramVec) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	for _, key := range sortedKeys(h.keys) {
+		labelValues, hist := h.keys[key], h.histograms[key]
+		for i, bound := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, labelValues, "le", formatFloat(bound)), hist.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, labelValues, "le", "+Inf"), hist.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, labelValues), formatFloat(hist.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, labelValues), hist.count)
+	}
+}
+
+func sortedKeys(keys map[string][]string) []string {
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// Metrics is the registry of the metrics of the clients and servers of the process.
+var Metrics = &MetricsRegistry{}
+
+var (
+	upstreamRequests = newCounterVec(Metrics, "web_helper_upstream_requests_total",
+		"Innertube requests by endpoint, client profile and HTTP status, error for transport failures.",
+		"endpoint", "profile", "status")
+	upstreamDuration = newHistogramVec(Metrics, "web_helper_upstream_request_duration_seconds",
+		"Latency of the innertube requests by endpoint and client profile.",
+		metricsDurationBuckets, "endpoint", "profile")
+	playerLookups = newCounterVec(Metrics, "web_helper_player_lookups_total",
+		"GetPlayerResponse calls by playability status, error if the lookup failed.",
+		"playability")
+	playerLookupDuration = newHistogramVec(Metrics, "web_helper_player_lookup_duration_seconds",
+		"Latency of the GetPlayerResponse calls, including retries, coalescing and the cache.",
+		metricsDurationBuckets)
+	cacheLookups = newCounterVec(Metrics, "web_helper_cache_lookups_total",
+		"Player response cache lookups by result: hit, miss or error.",
+		"result")
+	coalescedLookups = newCounterVec(Metrics, "web_helper_coalesced_lookups_total",
+		"GetPlayerResponse calls which shared the upstream call of a concurrent lookup of the same video.")
+	streamedBytes = newCounterVec(Metrics, "web_helper_streamed_bytes_total",
+		"Media bytes streamed through the stream endpoint.")
+	httpRequests = newCounterVec(Metrics, "web_helper_http_requests_total",
+		"Requests served by handler and HTTP status.",
+		"handler", "code")
+	httpDuration = newHistogramVec(Metrics, "web_helper_http_request_duration_seconds",
+		"Latency of the served requests by handler, until the response is complete.",
+		metricsDurationBuckets, "handler")
+	grpcRequests = newCounterVec(Metrics, "web_helper_grpc_requests_total",
+		"gRPC calls served by method and status code.",
+		"method", "code")
+	grpcDuration = newHistogramVec(Metrics, "web_helper_grpc_request_duration_seconds",
+		"Latency of the gRPC calls served by method, until the call or its stream ends.",
+		metricsDurationBuckets, "method")
+)
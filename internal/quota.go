@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// QuotaStore counts the requests of every caller per UTC day.
+type QuotaStore interface {
+	// Increment counts a request of the caller on the day, e.g. 2023-06-01, and returns
+	// the number of requests of the day so far.
+	Increment(caller string, day string) (int, error)
+}
+
+// MemoryQuotaStore keeps the counts in memory, they are lost on restart.
+type MemoryQuotaStore struct {
+	mu     sync.Mutex
+	day    string
+	counts map[string]int
+}
+
+func (s *MemoryQuotaStore) Increment(caller string, day string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if day != s.day {
+		s.day = day
+		s.counts = map[string]int{}
+	}
+	s.counts[caller]++
+	return s.counts[caller], nil
+}
+
+// DiskQuotaStore keeps the counts of the current day in a JSON file, so they survive
+// restarts. The file is rewritten on every request.
+type DiskQuotaStore struct {
+	Path string
+
+	mu     sync.Mutex
+	loaded bool
+	state  diskQuotaState
+}
+
+type diskQuotaState struct {
+	Day    string         `json:"day"`
+	Counts map[string]int `json:"counts"`
+}
+
+func (s *DiskQuotaStore) Increment(caller string, day string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.loaded {
+		content, err := os.ReadFile(s.Path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(content, &s.state); err != nil {
+				return 0, err
+			}
+		case !os.IsNotExist(err):
+			return 0, err
+		}
+		s.loaded = true
+	}
+
+	if day != s.state.Day || s.state.Counts == nil {
+		s.state = diskQuotaState{Day: day, Counts: map[string]int{}}
+	}
+	s.state.Counts[caller]++
+
+	content, err := json.Marshal(s.state)
+	if err != nil {
+		return 0, err
+	}
+	temp := s.Path + ".tmp"
+	if err := os.WriteFile(temp, content, 0600); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(temp, s.Path); err != nil {
+		return 0, err
+	}
+	return s.state.Counts[caller], nil
+}
+
+// quotaDay returns the UTC day of the time and the time left until the next one.
+func quotaDay(now time.Time) (string, time.Duration) {
+	now = now.UTC()
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	return now.Format("2006-01-02"), tomorrow.Sub(now)
+}
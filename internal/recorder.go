@@ -0,0 +1,249 @@
+package internal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode/utf8"
+)
+
+// Exchange is a recorded HTTP request and its response.
+type Exchange struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers"`
+	Body    string      `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Headers    http.Header `json:"headers"`
+	Body       string      `json:"body"`
+	// BodyEncoding is base64 for binary bodies, empty for text.
+	BodyEncoding string `json:"bodyEncoding,omitempty"`
+}
+
+// exchangeCounter numbers the exchanges with the same key, so repeated identical
+// requests are recorded and replayed in order.
+type exchangeCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (c *exchangeCounter) next(key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = map[string]int{}
+	}
+	c.counts[key]++
+	return c.counts[key]
+}
+
+func exchangeFile(dir string, key string, sequence int) string {
+	return filepath.Join(dir, fmt.Sprintf("%s-%d.json", key, sequence))
+}
+
+// readRequestBody reads the request body and replaces it so the request can still be sent.
+func readRequestBody(request *http.Request) ([]byte, error) {
+	if request.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// exchangeKey identifies the request by method, URL, range and body.
+func exchangeKey(request *http.Request, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n%s\n", request.Method, request.URL.String(), request.Header.Get("Range"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))[:32]
+}
+
+// DefaultMaxRecordedBodySize is the MaxBodySize of a RecordingTransport leaving it 0.
+const DefaultMaxRecordedBodySize = 16 << 20
+
+// redactedHeaders carry the identity of the client, they are recorded as "REDACTED" so the
+// recordings can be shared. Replay doesn't match on headers other than Range.
+var redactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Goog-Visitor-Id"}
+
+// redactHeaders returns a copy of the headers with the identifying values redacted.
+func redactHeaders(headers http.Header) http.Header {
+	headers = headers.Clone()
+	for _, key := range redactedHeaders {
+		if values := headers.Values(key); len(values) > 0 {
+			redacted := make([]string, len(values))
+			for i := range redacted {
+				redacted[i] = "REDACTED"
+			}
+			headers[http.CanonicalHeaderKey(key)] = redacted
+		}
+	}
+	return headers
+}
+
+// RecordingTransport saves every exchange it sends into Dir.
+type RecordingTransport struct {
+	Dir string
+	// Transport sends the requests, nil means http.DefaultTransport.
+	Transport http.RoundTripper
+	// MaxBodySize caps the response bodies held in memory to record them, larger responses
+	// are passed through unrecorded. 0 means DefaultMaxRecordedBodySize.
+	MaxBodySize int64
+
+	counter exchangeCounter
+}
+
+func (t *RecordingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	requestBody, err := readRequestBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	response, err := transport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	maxBodySize := t.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultMaxRecordedBodySize
+	}
+	if response.ContentLength > maxBodySize {
+		return response, nil
+	}
+
+	responseBody, err := io.ReadAll(io.LimitReader(response.Body, maxBodySize+1))
+	if err != nil {
+		response.Body.Close()
+		return nil, err
+	}
+	if int64(len(responseBody)) > maxBodySize {
+		// Too large to record, pass on what was read followed by the rest.
+		response.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(responseBody), response.Body), response.Body}
+		return response, nil
+	}
+	response.Body.Close()
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	exchange := Exchange{
+		Request: RecordedRequest{
+			Method:  request.Method,
+			URL:     request.URL.String(),
+			Headers: redactHeaders(request.Header),
+			Body:    string(requestBody),
+		},
+		Response: RecordedResponse{
+			StatusCode: response.StatusCode,
+			Headers:    redactHeaders(response.Header),
+			Body:       string(responseBody),
+		},
+	}
+	if !utf8.Valid(responseBody) {
+		exchange.Response.Body = base64.StdEncoding.EncodeToString(responseBody)
+		exchange.Response.BodyEncoding = "base64"
+	}
+
+	serializedExchange, err := json.MarshalIndent(exchange, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to record exchange: %v", err)
+	}
+
+	key := exchangeKey(request, requestBody)
+	file := exchangeFile(t.Dir, key, t.counter.next(key))
+	if err := os.WriteFile(file, serializedExchange, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to record exchange: %v", err)
+	}
+
+	return response, nil
+}
+
+// ReplayTransport answers requests with the exchanges recorded into Dir without touching
+// the network. Repeated requests are answered in the recorded order, the last recorded
+// response is reused once they run out.
+type ReplayTransport struct {
+	Dir string
+
+	counter exchangeCounter
+}
+
+func (t *ReplayTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	requestBody, err := readRequestBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	key := exchangeKey(request, requestBody)
+	sequence := t.counter.next(key)
+
+	var serializedExchange []byte
+	for ; sequence > 0; sequence-- {
+		serializedExchange, err = os.ReadFile(exchangeFile(t.Dir, key, sequence))
+		if err == nil || !os.IsNotExist(err) {
+			break
+		}
+	}
+	if sequence == 0 {
+		return nil, fmt.Errorf("no recorded exchange for %s %s", request.Method, request.URL)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	exchange := Exchange{}
+	if err := json.Unmarshal(serializedExchange, &exchange); err != nil {
+		return nil, fmt.Errorf("failed to parse recorded exchange: %v", err)
+	}
+
+	responseBody := []byte(exchange.Response.Body)
+	if exchange.Response.BodyEncoding == "base64" {
+		responseBody, err = base64.StdEncoding.DecodeString(exchange.Response.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode recorded body: %v", err)
+		}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.Response.StatusCode, http.StatusText(exchange.Response.StatusCode)),
+		StatusCode:    exchange.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        exchange.Response.Headers,
+		Body:          io.NopCloser(bytes.NewReader(responseBody)),
+		ContentLength: int64(len(responseBody)),
+		Request:       request,
+	}, nil
+}
@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recordedExchange is a request made against the recording and the replaying transport.
+type recordedExchange struct {
+	method  string
+	path    string
+	rangeOf string
+	body    string
+}
+
+func (e recordedExchange) do(t *testing.T, client *http.Client, baseURL string) (int, []byte) {
+	t.Helper()
+
+	var body io.Reader
+	if e.body != "" {
+		body = strings.NewReader(e.body)
+	}
+	request, err := http.NewRequest(e.method, baseURL+e.path, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.rangeOf != "" {
+		request.Header.Set("Range", e.rangeOf)
+	}
+	request.Header.Set("Cookie", "SID=secret-cookie")
+	request.Header.Set("X-Goog-Visitor-Id", "secret-visitor")
+	request.Header.Set("Authorization", "Bearer secret-token")
+
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatalf("%s %s: %v", e.method, e.path, err)
+	}
+	defer response.Body.Close()
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response.StatusCode, responseBody
+}
+
+func TestRecordReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		http.SetCookie(w, &http.Cookie{Name: "VISITOR_INFO1_LIVE", Value: "secret-set-cookie"})
+		switch r.URL.Path {
+		case "/binary":
+			w.Write([]byte{0xff, 0x00, 0xfe, byte(calls)})
+		case "/missing":
+			http.NotFound(w, r)
+		default:
+			io.WriteString(w, r.Method+" "+r.URL.String()+" "+r.Header.Get("Range")+" "+string(body)+" "+string(rune('0'+calls)))
+		}
+	}))
+	dir := t.TempDir()
+
+	exchanges := []recordedExchange{
+		{method: http.MethodPost, path: "/player", body: `{"videoId":"a"}`},
+		{method: http.MethodPost, path: "/player", body: `{"videoId":"b"}`},
+		{method: http.MethodPost, path: "/player", body: `{"videoId":"a"}`},
+		{method: http.MethodGet, path: "/media?itag=18"},
+		{method: http.MethodGet, path: "/media?itag=18", rangeOf: "bytes=0-99"},
+		{method: http.MethodGet, path: "/media?itag=18", rangeOf: "bytes=100-199"},
+		{method: http.MethodGet, path: "/binary"},
+		{method: http.MethodGet, path: "/missing"},
+	}
+
+	recording := &http.Client{Transport: &RecordingTransport{Dir: dir}}
+	type result struct {
+		status int
+		body   []byte
+	}
+	var recorded []result
+	for _, exchange := range exchanges {
+		status, body := exchange.do(t, recording, server.URL)
+		recorded = append(recorded, result{status, body})
+	}
+	server.Close()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(exchanges) {
+		t.Errorf("recorded %d files, want %d", len(files), len(exchanges))
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(content, []byte("secret")) {
+			t.Errorf("%s is not redacted:\n%s", file, content)
+		}
+	}
+
+	// The server is gone, the answers come from the recordings in the same order.
+	replaying := &http.Client{Transport: &ReplayTransport{Dir: dir}}
+	for i, exchange := range exchanges {
+		status, body := exchange.do(t, replaying, server.URL)
+		if status != recorded[i].status || !bytes.Equal(body, recorded[i].body) {
+			t.Errorf("replayed %s %s %s = %d %q, want %d %q", exchange.method, exchange.path, exchange.rangeOf,
+				status, body, recorded[i].status, recorded[i].body)
+		}
+	}
+
+	// Running out of recordings reuses the last one.
+	status, body := exchanges[0].do(t, replaying, server.URL)
+	if status != recorded[2].status || !bytes.Equal(body, recorded[2].body) {
+		t.Errorf("replayed %d %q once run out, want the last recording %q", status, body, recorded[2].body)
+	}
+
+	unrecorded := []recordedExchange{
+		{method: http.MethodPost, path: "/player", body: `{"videoId":"c"}`},
+		{method: http.MethodGet, path: "/media?itag=18", rangeOf: "bytes=200-299"},
+		{method: http.MethodGet, path: "/media?itag=22"},
+		{method: http.MethodHead, path: "/binary"},
+	}
+	for _, exchange := range unrecorded {
+		request, _ := http.NewRequest(exchange.method, server.URL+exchange.path, strings.NewReader(exchange.body))
+		if exchange.rangeOf != "" {
+			request.Header.Set("Range", exchange.rangeOf)
+		}
+		if response, err := replaying.Do(request); err == nil {
+			response.Body.Close()
+			t.Errorf("replayed unrecorded %s %s %s", exchange.method, exchange.path, exchange.rangeOf)
+		}
+	}
+}
+
+func TestRecordMaxBodySize(t *testing.T) {
+	large := bytes.Repeat([]byte("x"), 1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("chunked") != "" {
+			// Without a Content-Length the size is only known once read.
+			w.(http.Flusher).Flush()
+		}
+		w.Write(large)
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	client := &http.Client{Transport: &RecordingTransport{Dir: dir, MaxBodySize: 100}}
+
+	for _, path := range []string{"/", "/?chunked=1"} {
+		response, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(body, large) {
+			t.Errorf("%s: passed on %d bytes, want %d", path, len(body), len(large))
+		}
+	}
+
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.json")); len(files) != 0 {
+		t.Errorf("recorded %d bodies over the limit", len(files))
+	}
+}
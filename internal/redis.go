@@ -0,0 +1,265 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RedisCache is a Cache on a Redis server, speaking RESP over pooled connections.
+type RedisCache struct {
+	Addr     string
+	Username string
+	Password string
+	DB       int
+	// Timeout limits every command on top of the context deadline.
+	Timeout time.Duration
+	// MaxIdle is the number of connections kept open between commands.
+	MaxIdle int
+
+	mu   sync.Mutex
+	idle []*redisConn
+}
+
+// NewRedisCache returns the cache of a redis://[user:password@]host[:port][/db] URL.
+func NewRedisCache(rawURL string) (*RedisCache, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Scheme != "redis" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid Redis URL: %s", rawURL)
+	}
+
+	cache := &RedisCache{Addr: parsed.Host, Timeout: 5 * time.Second, MaxIdle: 8}
+	if parsed.Port() == "" {
+		cache.Addr = net.JoinHostPort(parsed.Hostname(), "6379")
+	}
+	if parsed.User != nil {
+		cache.Username = parsed.User.Username()
+		cache.Password, _ = parsed.User.Password()
+	}
+	if db := strings.TrimPrefix(parsed.Path, "/"); db != "" {
+		if cache.DB, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid Redis database: %s", db)
+		}
+	}
+	return cache, nil
+}
+
+// RedisError is an error reply of the server.
+type RedisError struct {
+	Message string
+}
+
+func (e *RedisError) Error() string {
+	return "redis: " + e.Message
+}
+
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	reply, err := c.do(ctx, "GET", key)
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, ErrCacheMiss
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("redis: unexpected GET reply %v", reply)
+	}
+	return value, nil
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	milliseconds := ttl.Milliseconds()
+	if milliseconds < 1 {
+		milliseconds = 1
+	}
+	_, err := c.do(ctx, "SET", key, value, "PX", strconv.FormatInt(milliseconds, 10))
+	return err
+}
+
+func (c *RedisCache) Delete(ctx context.Context, key string) error {
+	_, err := c.do(ctx, "DEL", key)
+	return err
+}
+
+// do sends the command and returns the reply: nil, []byte, int64 or []interface{}.
+func (c *RedisCache) do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	for {
+		conn, pooled, err := c.conn(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		reply, err := conn.roundTrip(ctx, args...)
+		var redisErr *RedisError
+		if err != nil && !errors.As(err, &redisErr) {
+			// The connection state is unknown after a network or protocol error.
+			conn.conn.Close()
+			// The server may have closed the connection while it was idle, the command
+			// is tried again on the next one. The commands of the cache are idempotent.
+			if pooled && ctx.Err() == nil {
+				continue
+			}
+			return nil, err
+		}
+
+		c.release(conn)
+		return reply, err
+	}
+}
+
+// conn returns an idle connection with pooled true, or dials a new one.
+func (c *RedisCache) conn(ctx context.Context) (conn *redisConn, pooled bool, err error) {
+	c.mu.Lock()
+	if n := len(c.idle); n > 0 {
+		conn = c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return conn, true, nil
+	}
+	c.mu.Unlock()
+
+	var dialer net.Dialer
+	netConn, err := dialer.DialContext(ctx, "tcp", c.Addr)
+	if err != nil {
+		return nil, false, err
+	}
+	conn = &redisConn{conn: netConn, reader: bufio.NewReader(netConn), writer: bufio.NewWriter(netConn)}
+
+	if c.Password != "" {
+		args := []interface{}{"AUTH", c.Password}
+		if c.Username != "" {
+			args = []interface{}{"AUTH", c.Username, c.Password}
+		}
+		if _, err := conn.roundTrip(ctx, args...); err != nil {
+			netConn.Close()
+			return nil, false, err
+		}
+	}
+	if c.DB != 0 {
+		if _, err := conn.roundTrip(ctx, "SELECT", strconv.Itoa(c.DB)); err != nil {
+			netConn.Close()
+			return nil, false, err
+		}
+	}
+	return conn, false, nil
+}
+
+func (c *RedisCache) release(conn *redisConn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.idle) >= c.MaxIdle {
+		conn.conn.Close()
+		return
+	}
+	c.idle = append(c.idle, conn)
+}
+
+func (c *redisConn) roundTrip(ctx context.Context, args ...interface{}) (interface{}, error) {
+	deadline, _ := ctx.Deadline()
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if err := writeRESPCommand(c.writer, args...); err != nil {
+		return nil, err
+	}
+	if err := c.writer.Flush(); err != nil {
+		return nil, err
+	}
+	return readRESP(c.reader)
+}
+
+// writeRESPCommand writes the command as an array of bulk strings.
+func writeRESPCommand(writer *bufio.Writer, args ...interface{}) error {
+	fmt.Fprintf(writer, "*%d\r\n", len(args))
+	for _, arg := range args {
+		var value []byte
+		switch arg := arg.(type) {
+		case string:
+			value = []byte(arg)
+		case []byte:
+			value = arg
+		default:
+			return fmt.Errorf("redis: unsupported argument %T", arg)
+		}
+		fmt.Fprintf(writer, "$%d\r\n", len(value))
+		writer.Write(value)
+		if _, err := writer.WriteString("\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readRESP reads a reply: nil, []byte for simple and bulk strings, int64 or
+// []interface{}, error replies as *RedisError.
+func readRESP(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("redis: invalid reply %q", line)
+	}
+	kind, payload := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return []byte(payload), nil
+	case '-':
+		return nil, &RedisError{Message: payload}
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		length, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length %q", payload)
+		}
+		if length < 0 {
+			return nil, nil
+		}
+		value := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, value); err != nil {
+			return nil, err
+		}
+		return value[:length], nil
+	case '*':
+		count, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid array length %q", payload)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		values := make([]interface{}, count)
+		for i := range values {
+			if values[i], err = readRESP(reader); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("redis: invalid reply %q", line)
+}
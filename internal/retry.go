@@ -0,0 +1,212 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// StatusError is returned when the upstream answers with a non-200 status.
+type StatusError struct {
+	StatusCode int
+	// RetryAfter is the delay requested by the Retry-After header, 0 if absent.
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("request failed with status code: %d", e.StatusCode)
+}
+
+// overloaded reports whether the status means the upstream is rate limiting or failing.
+func (e *StatusError) overloaded() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+func newStatusError(response *http.Response, now time.Time) *StatusError {
+	return &StatusError{
+		StatusCode: response.StatusCode,
+		RetryAfter: parseRetryAfter(response.Header.Get("Retry-After"), now),
+	}
+}
+
+// parseRetryAfter parses the Retry-After header given either in seconds or as a date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// RetryPolicy configures how failed idempotent requests are retried: transient transport errors
+// and 429/5xx statuses are retried with exponential backoff and jitter.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, 1 or less disables retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts. A Retry-After longer than that is not
+	// waited for and the request fails right away.
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after every attempt.
+	Multiplier float64
+	// Jitter is the fraction of the delay randomized away, 0 to 1.
+	Jitter float64
+}
+
+// DefaultRetryPolicy returns the retry policy of NewClient.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.5,
+	}
+}
+
+// backoff returns the delay before the attempt following the failed one, starting at 1.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	delay -= delay * p.Jitter * rand.Float64()
+	return time.Duration(delay)
+}
+
+// retryDelay returns the delay before retrying the failed attempt, or false if the error
+// must not be retried.
+func (p RetryPolicy) retryDelay(ctx context.Context, attempt int, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return 0, false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		if !statusErr.overloaded() {
+			return 0, false
+		}
+		delay := p.backoff(attempt)
+		if statusErr.RetryAfter > 0 {
+			if p.MaxBackoff > 0 && statusErr.RetryAfter > p.MaxBackoff {
+				return 0, false
+			}
+			if statusErr.RetryAfter > delay {
+				delay = statusErr.RetryAfter
+			}
+		}
+		return delay, true
+	}
+
+	if transientError(err) {
+		return p.backoff(attempt), true
+	}
+
+	return 0, false
+}
+
+// transientError reports whether the transport error may not happen again: a timeout, a
+// refused or reset connection or a truncated response. Other errors, like TLS failures,
+// unsupported schemes, ErrNoProxies or a missing recorded exchange, fail fast.
+func transientError(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// CircuitOpenError is returned instead of sending requests while the breaker is open.
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker open, upstream is failing: retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+// CircuitBreaker stops requests to the upstream after sustained 429/5xx responses, so
+// callers fail fast instead of amplifying the load. After the cooldown a single trial
+// request is let through: success closes the breaker, failure opens it again.
+type CircuitBreaker struct {
+	// Threshold is the number of consecutive overload failures tripping the breaker.
+	Threshold int
+	// Cooldown is how long the breaker stays open.
+	Cooldown time.Duration
+
+	mu         sync.Mutex
+	failures   int
+	openUntil  time.Time
+	trialAllow bool
+}
+
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{Threshold: threshold, Cooldown: cooldown}
+}
+
+// Allow returns a CircuitOpenError if the request must not be sent.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openUntil.IsZero() {
+		return nil
+	}
+
+	now := time.Now()
+	if now.Before(b.openUntil) {
+		return &CircuitOpenError{RetryAfter: b.openUntil.Sub(now)}
+	}
+
+	// Half-open: let a single trial request through.
+	if !b.trialAllow {
+		return &CircuitOpenError{RetryAfter: b.Cooldown}
+	}
+	b.trialAllow = false
+	return nil
+}
+
+// Record updates the breaker with the outcome of a request sent after Allow.
+func (b *CircuitBreaker) Record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var statusErr *StatusError
+	var urlErr *url.Error
+	switch {
+	case errors.As(err, &statusErr) && statusErr.overloaded():
+		b.failures++
+		if b.failures >= b.Threshold || !b.openUntil.IsZero() {
+			b.openUntil = time.Now().Add(b.Cooldown)
+			b.trialAllow = true
+		}
+	case errors.As(err, &urlErr):
+		// A transport failure says nothing about the upstream load, let another trial through.
+		if !b.openUntil.IsZero() {
+			b.trialAllow = true
+		}
+	default:
+		// The upstream answered, even if with an error of its own.
+		b.failures = 0
+		b.openUntil = time.Time{}
+		b.trialAllow = false
+	}
+}
@@ -0,0 +1,259 @@
+package internal
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{"0", 0},
+		{"-1", 0},
+		{"soon", 0},
+		{now.Add(time.Minute).Format(http.TimeFormat), time.Minute},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+	}
+
+	for _, test := range tests {
+		if got := parseRetryAfter(test.value, now); got != test.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", test.value, got, test.want)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		attempt   int
+		err       error
+		wantDelay time.Duration
+		wantRetry bool
+	}{
+		{"too many requests", nil, 1, &StatusError{StatusCode: 429}, 100 * time.Millisecond, true},
+		{"backoff grows", nil, 2, &StatusError{StatusCode: 503}, 200 * time.Millisecond, true},
+		{"retry after", nil, 1, &StatusError{StatusCode: 503, RetryAfter: 500 * time.Millisecond}, 500 * time.Millisecond, true},
+		{"retry after shorter than backoff", nil, 2, &StatusError{StatusCode: 503, RetryAfter: time.Millisecond}, 200 * time.Millisecond, true},
+		{"retry after over max backoff", nil, 1, &StatusError{StatusCode: 429, RetryAfter: time.Minute}, 0, false},
+		{"client error", nil, 1, &StatusError{StatusCode: 404}, 0, false},
+		{"last attempt", nil, 3, &StatusError{StatusCode: 503}, 0, false},
+		{"canceled", canceled, 1, &StatusError{StatusCode: 503}, 0, false},
+		{"connection refused", nil, 1, &url.Error{Op: "Post", URL: "http://yt", Err: syscall.ECONNREFUSED}, 100 * time.Millisecond, true},
+		{"connection reset", nil, 1, &url.Error{Op: "Post", URL: "http://yt", Err: syscall.ECONNRESET}, 100 * time.Millisecond, true},
+		{"truncated response", nil, 1, io.ErrUnexpectedEOF, 100 * time.Millisecond, true},
+		{"timeout", nil, 1, &url.Error{Op: "Post", URL: "http://yt", Err: context.DeadlineExceeded}, 100 * time.Millisecond, true},
+		{"tls failure", nil, 1, &url.Error{Op: "Post", URL: "https://yt", Err: x509.UnknownAuthorityError{}}, 0, false},
+		{"no proxies", nil, 1, &url.Error{Op: "Post", URL: "http://yt", Err: ErrNoProxies}, 0, false},
+		{"other error", nil, 1, errors.New("failed"), 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := test.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			delay, retry := policy.retryDelay(ctx, test.attempt, test.err)
+			if delay != test.wantDelay || retry != test.wantRetry {
+				t.Errorf("retryDelay = %s, %t, want %s, %t", delay, retry, test.wantDelay, test.wantRetry)
+			}
+		})
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 4 * time.Second, Multiplier: 2, Jitter: 0.5}
+	for attempt := 1; attempt <= 5; attempt++ {
+		max := policy.InitialBackoff << (attempt - 1)
+		if max > policy.MaxBackoff {
+			max = policy.MaxBackoff
+		}
+		for i := 0; i < 100; i++ {
+			if delay := policy.backoff(attempt); delay < max/2 || delay > max {
+				t.Fatalf("backoff(%d) = %s, want between %s and %s", attempt, delay, max/2, max)
+			}
+		}
+	}
+}
+
+// statusServer answers with the statuses in turn, then with 200 and the player response.
+func statusServer(t *testing.T, retryAfter string, statuses ...int) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&requests, 1))
+		if n <= len(statuses) {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		io.WriteString(w, okPlayerResponse)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func newRetryTestClient(baseURL string) *Client {
+	client := NewClient()
+	client.BaseURL = baseURL
+	client.Coalesce = false
+	client.Retry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 100 * time.Millisecond, Multiplier: 2}
+	client.Breaker = nil
+	return client
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		retryAfter   string
+		wantRequests int32
+		wantStatus   int
+	}{
+		{"success", nil, "", 1, 0},
+		{"recovers from overload", []int{429, 503}, "", 3, 0},
+		{"gives up after max attempts", []int{503, 503, 503}, "", 3, 503},
+		{"client error", []int{403}, "", 1, 403},
+		{"retry after over max backoff", []int{429}, "60", 1, 429},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, requests := statusServer(t, test.retryAfter, test.statuses...)
+			client := newRetryTestClient(server.URL)
+
+			_, err := client.GetPlayerResponse(context.Background(), "dQw4w9WgXcQ")
+			var statusErr *StatusError
+			switch {
+			case test.wantStatus == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case test.wantStatus != 0 && (!errors.As(err, &statusErr) || statusErr.StatusCode != test.wantStatus):
+				t.Fatalf("error = %v, want status %d", err, test.wantStatus)
+			}
+			if got := atomic.LoadInt32(requests); got != test.wantRequests {
+				t.Errorf("got %d requests, want %d", got, test.wantRequests)
+			}
+		})
+	}
+}
+
+func TestClientRetryAfter(t *testing.T) {
+	server, requests := statusServer(t, "1", 503)
+	client := newRetryTestClient(server.URL)
+	client.Retry.MaxBackoff = 2 * time.Second
+
+	start := time.Now()
+	if _, err := client.GetPlayerResponse(context.Background(), "dQw4w9WgXcQ"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, want the requested 1s", elapsed)
+	}
+	if got := atomic.LoadInt32(requests); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	overloaded := &StatusError{StatusCode: 503}
+	breaker := NewCircuitBreaker(2, 50*time.Millisecond)
+
+	breaker.Record(overloaded)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("open after 1 failure: %v", err)
+	}
+	breaker.Record(nil)
+	breaker.Record(overloaded)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("a success didn't reset the failures: %v", err)
+	}
+	breaker.Record(&StatusError{StatusCode: 404})
+	breaker.Record(overloaded)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("a client error didn't reset the failures: %v", err)
+	}
+
+	breaker.Record(overloaded)
+	var circuitErr *CircuitOpenError
+	if err := breaker.Allow(); !errors.As(err, &circuitErr) || circuitErr.RetryAfter <= 0 {
+		t.Fatalf("Allow = %v, want open", err)
+	}
+
+	// Half-open: a single trial, failing opens the breaker again.
+	time.Sleep(60 * time.Millisecond)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("trial not allowed: %v", err)
+	}
+	if err := breaker.Allow(); !errors.As(err, &circuitErr) {
+		t.Fatalf("second trial allowed: %v", err)
+	}
+	breaker.Record(overloaded)
+	if err := breaker.Allow(); !errors.As(err, &circuitErr) {
+		t.Fatalf("Allow = %v after a failed trial, want open", err)
+	}
+
+	// A transport failure lets another trial through.
+	time.Sleep(60 * time.Millisecond)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("trial not allowed: %v", err)
+	}
+	breaker.Record(&url.Error{Op: "Post", URL: "http://yt", Err: syscall.ECONNREFUSED})
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("trial after a transport failure not allowed: %v", err)
+	}
+
+	// A successful trial closes the breaker.
+	breaker.Record(nil)
+	for i := 0; i < 3; i++ {
+		if err := breaker.Allow(); err != nil {
+			t.Fatalf("Allow = %v after a successful trial, want closed", err)
+		}
+	}
+}
+
+func TestClientCircuitBreaker(t *testing.T) {
+	server, requests := statusServer(t, "", 503, 503, 503, 503)
+	client := newRetryTestClient(server.URL)
+	client.Retry.MaxAttempts = 1
+	client.Breaker = NewCircuitBreaker(2, time.Minute)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetPlayerResponse(ctx, "dQw4w9WgXcQ"); err == nil {
+			t.Fatal("succeeded while overloaded")
+		}
+	}
+
+	var circuitErr *CircuitOpenError
+	if _, err := client.GetPlayerResponse(ctx, "dQw4w9WgXcQ"); !errors.As(err, &circuitErr) {
+		t.Fatalf("error = %v, want CircuitOpenError", err)
+	}
+	if got := atomic.LoadInt32(requests); got != 2 {
+		t.Errorf("got %d requests, want 2 before the breaker opened", got)
+	}
+}
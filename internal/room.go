@@ -0,0 +1,566 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// roomMaxMembers bounds the members of a room.
+	roomMaxMembers = 200
+	// roomSendQueue is how many messages a member can lag behind before it is dropped, so
+	// a slow member does not hold the room back.
+	roomSendQueue = 64
+	// roomDriftTolerance is the drift in seconds between a member and the room left
+	// uncorrected.
+	roomDriftTolerance = 0.25
+	// roomSeekDrift is the drift in seconds from which a member should seek instead of
+	// adjusting its playback rate.
+	roomSeekDrift = 2.0
+	// roomMaxRateAdjustment bounds the playback rate change suggested to catch up, the
+	// rate closes the drift in about ten seconds.
+	roomMaxRateAdjustment = 0.05
+)
+
+var roomIdPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// RoomRequest is a message of a member of a playback room. Load, play, pause and seek
+// are reserved to the host unless the room is open, host and open to the host.
+type RoomRequest struct {
+	// Type is load, play, pause, seek, host, open, ping or position.
+	Type string `json:"type"`
+	// VideoId and Select are the video to load and the selection of its streams in the
+	// yt --select syntax.
+	VideoId string `json:"videoId,omitempty"`
+	Select  string `json:"select,omitempty"`
+	// Position is the position to seek to, or the position reported by the member, in
+	// seconds.
+	Position float64 `json:"position,omitempty"`
+	// At is when the reported position was sampled, in unix milliseconds of the server
+	// clock as estimated by the member. The time of arrival if zero.
+	At int64 `json:"at,omitempty"`
+	// MemberId is the member the host role is handed over to.
+	MemberId string `json:"memberId,omitempty"`
+	// Open lets every member control the playback.
+	Open bool `json:"open,omitempty"`
+	// ClientTime is echoed in the pong, so the member can estimate the server clock.
+	ClientTime int64 `json:"clientTime,omitempty"`
+}
+
+// RoomMessage is a message sent to the members of a room: welcome on join, state on
+// every change of the room, pong, drift in reply to a position and error.
+type RoomMessage struct {
+	Type       string     `json:"type"`
+	MemberId   string     `json:"memberId,omitempty"`
+	State      *RoomState `json:"state,omitempty"`
+	Drift      *DriftHint `json:"drift,omitempty"`
+	ClientTime int64      `json:"clientTime,omitempty"`
+	// ServerTime is the server clock in unix milliseconds when the message was sent.
+	ServerTime int64      `json:"serverTime"`
+	Error      *ErrorBody `json:"error,omitempty"`
+}
+
+// RoomState is the shared playback state of a room.
+type RoomState struct {
+	RoomId string `json:"roomId"`
+	// Version increases with every change of the state.
+	Version int          `json:"version"`
+	HostId  string       `json:"hostId"`
+	Open    bool         `json:"open"`
+	Members []RoomMember `json:"members"`
+	VideoId string       `json:"videoId,omitempty"`
+	// Descriptor is the descriptor of the video, sent again with fresh URLs before they
+	// expire.
+	Descriptor *Descriptor `json:"descriptor,omitempty"`
+	Playing    bool        `json:"playing"`
+	// Position is the playback position in seconds at UpdatedAt, in unix milliseconds of
+	// the server clock. While playing, the position advances with the clock from there.
+	Position  float64 `json:"position"`
+	UpdatedAt int64   `json:"updatedAt"`
+}
+
+type RoomMember struct {
+	Id   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// DriftHint tells a member how to catch up with the room.
+type DriftHint struct {
+	// Drift is the position of the member minus the position of the room in seconds,
+	// positive when the member is ahead.
+	Drift float64 `json:"drift"`
+	// Action is none, rate to play at Rate until the next hint, or seek to Position.
+	Action string  `json:"action"`
+	Rate   float64 `json:"rate,omitempty"`
+	// Position is the position of the room at the ServerTime of the message.
+	Position float64 `json:"position"`
+}
+
+// roomHub holds the rooms of the server by ID, a room exists while it has members.
+type roomHub struct {
+	mu    sync.Mutex
+	rooms map[string]*room
+}
+
+// join adds the member to the room, creating the room with the member as its host.
+func (h *roomHub) join(server *Server, roomID string, member *roomMember) (*room, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.rooms == nil {
+		h.rooms = map[string]*room{}
+	}
+	r, found := h.rooms[roomID]
+	if !found {
+		r = &room{id: roomID, server: server}
+		h.rooms[roomID] = r
+	}
+	return r, r.add(member)
+}
+
+// leave removes the member from the room, closing the room when it was the last one.
+func (h *roomHub) leave(r *room, member *roomMember) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if r.remove(member) == 0 && h.rooms[r.id] == r {
+		delete(h.rooms, r.id)
+	}
+}
+
+// room is a playback room. Its state changes with the requests of its members, every
+// change is broadcast to all of them.
+type room struct {
+	id     string
+	server *Server
+
+	mu sync.Mutex
+	// members are in the order they joined, the first is promoted when the host leaves.
+	members []*roomMember
+	hostID  string
+	open    bool
+	version int
+	closed  bool
+
+	videoID    string
+	selector   Selector
+	descriptor *Descriptor
+	// request is the request of the member who loaded the video, whose host the proxied
+	// stream URLs point to.
+	request *http.Request
+	// loads counts the loaded videos, so a refresh of a replaced video is dropped.
+	loads   int
+	refresh *time.Timer
+
+	playing   bool
+	position  float64
+	updatedAt time.Time
+}
+
+// roomMember is a connection to a room. Messages are queued to its writer, so a
+// broadcast never waits for the network.
+type roomMember struct {
+	id   string
+	name string
+	conn *websocket.Conn
+	send chan RoomMessage
+}
+
+// handleRoom joins the room of the roomId query parameter over a WebSocket, creating the
+// room with the caller as host if it does not exist. The name parameter is shown to the
+// other members.
+func (s *Server) handleRoom(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	roomID := query.Get("roomId")
+	if !roomIdPattern.MatchString(roomID) {
+		writeError(w, http.StatusBadRequest, "invalid roomId, expected 1 to 64 letters, digits, - or _")
+		return
+	}
+	name := query.Get("name")
+	if len(name) > 64 {
+		writeError(w, http.StatusBadRequest, "name too long")
+		return
+	}
+
+	conn, err := s.upgradeWebSocket(w, r)
+	if err != nil {
+		// The upgrader replied with the error already.
+		return
+	}
+	defer conn.Close()
+
+	member := &roomMember{id: newRequestID(), name: name, conn: conn, send: make(chan RoomMessage, roomSendQueue)}
+	joined, err := s.rooms.join(s, roomID, member)
+	if err != nil {
+		_ = conn.SetWriteDeadline(time.Now().Add(watchWriteTimeout))
+		_ = conn.WriteJSON(roomError(http.StatusServiceUnavailable, err.Error(), time.Now()))
+		return
+	}
+	go member.write()
+	defer s.rooms.leave(joined, member)
+	s.client.log(r.Context(), LevelDebug, "joined room", "room", roomID, "member", member.id)
+
+	extendDeadline := func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * watchPingInterval))
+	}
+	_ = extendDeadline("")
+	conn.SetPongHandler(extendDeadline)
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			s.client.log(r.Context(), LevelDebug, "left room", "room", roomID, "member", member.id, "error", err)
+			return
+		}
+		_ = extendDeadline("")
+
+		var request RoomRequest
+		if err := json.Unmarshal(data, &request); err != nil {
+			joined.reply(member, roomError(http.StatusBadRequest, "invalid message: "+err.Error(), time.Now()))
+			continue
+		}
+		joined.handle(r, member, request)
+	}
+}
+
+// write sends the queued messages and pings the member until its queue is closed.
+func (m *roomMember) write() {
+	ticker := time.NewTicker(watchPingInterval)
+	defer ticker.Stop()
+
+	failed := false
+	for {
+		var err error
+		select {
+		case message, ok := <-m.send:
+			if !ok {
+				return
+			}
+			if failed {
+				continue
+			}
+			_ = m.conn.SetWriteDeadline(time.Now().Add(watchWriteTimeout))
+			err = m.conn.WriteJSON(message)
+		case <-ticker.C:
+			err = m.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(watchWriteTimeout))
+		}
+		// Closing the connection stops the read loop, which leaves the room.
+		if err != nil && !failed {
+			failed = true
+			m.conn.Close()
+		}
+	}
+}
+
+// push queues the message, dropping the member if it lags too far behind. The room lock
+// must be held.
+func (m *roomMember) push(message RoomMessage) {
+	select {
+	case m.send <- message:
+	default:
+		m.conn.Close()
+	}
+}
+
+func (r *room) add(member *roomMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return errors.New("room closed")
+	}
+	if len(r.members) >= roomMaxMembers {
+		return errors.New("room full")
+	}
+	r.members = append(r.members, member)
+	if r.hostID == "" {
+		r.hostID = member.id
+	}
+
+	now := time.Now()
+	r.changed(now, member)
+	member.push(RoomMessage{Type: "welcome", MemberId: member.id, State: r.state(now), ServerTime: now.UnixMilli()})
+	return nil
+}
+
+// remove removes the member, handing the host role over to the member who joined first
+// if needed, and returns the number of members left.
+func (r *room) remove(member *roomMember) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, candidate := range r.members {
+		if candidate == member {
+			r.members = append(r.members[:i], r.members[i+1:]...)
+			close(member.send)
+			break
+		}
+	}
+	if len(r.members) == 0 {
+		r.closed = true
+		if r.refresh != nil {
+			r.refresh.Stop()
+		}
+		return 0
+	}
+
+	if r.hostID == member.id {
+		r.hostID = r.members[0].id
+	}
+	r.changed(time.Now(), nil)
+	return len(r.members)
+}
+
+func (r *room) handle(request *http.Request, member *roomMember, message RoomRequest) {
+	if message.Type == "load" {
+		r.load(request, member, message)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	switch message.Type {
+	case "ping":
+		member.push(RoomMessage{Type: "pong", ClientTime: message.ClientTime, ServerTime: now.UnixMilli()})
+	case "position":
+		at := now
+		if message.At > 0 {
+			at = time.UnixMilli(message.At)
+		}
+		member.push(RoomMessage{Type: "drift", Drift: r.driftHint(message.Position, at, now), ServerTime: now.UnixMilli()})
+	case "play", "pause", "seek":
+		if !r.mayControl(member) {
+			member.push(roomError(http.StatusForbidden, "only the host can "+message.Type, now))
+			return
+		}
+		if r.descriptor == nil {
+			member.push(roomError(http.StatusConflict, "no video loaded", now))
+			return
+		}
+		if message.Type == "seek" && (message.Position < 0 || math.IsNaN(message.Position)) {
+			member.push(roomError(http.StatusBadRequest, "invalid position", now))
+			return
+		}
+
+		position := r.positionAt(now)
+		if message.Type == "seek" {
+			position = r.clamp(message.Position)
+		}
+		playing := message.Type == "play" || message.Type == "seek" && r.playing
+		r.position, r.playing, r.updatedAt = position, playing, now
+		r.changed(now, nil)
+	case "host", "open":
+		if member.id != r.hostID {
+			member.push(roomError(http.StatusForbidden, "only the host can "+message.Type, now))
+			return
+		}
+		if message.Type == "open" {
+			r.open = message.Open
+		} else if r.member(message.MemberId) != nil {
+			r.hostID = message.MemberId
+		} else {
+			member.push(roomError(http.StatusNotFound, "unknown member: "+message.MemberId, now))
+			return
+		}
+		r.changed(now, nil)
+	default:
+		member.push(roomError(http.StatusBadRequest, "unknown message type: "+message.Type, now))
+	}
+}
+
+// load resolves the video and makes it the video of the room, paused at the start. The
+// room is not locked while resolving.
+func (r *room) load(request *http.Request, member *roomMember, message RoomRequest) {
+	r.mu.Lock()
+	allowed := r.mayControl(member)
+	r.mu.Unlock()
+	if !allowed {
+		r.reply(member, roomError(http.StatusForbidden, "only the host can load", time.Now()))
+		return
+	}
+	if message.VideoId == "" {
+		r.reply(member, roomError(http.StatusBadRequest, "missing videoId", time.Now()))
+		return
+	}
+	selector, err := ParseSelector(message.Select)
+	if err != nil {
+		r.reply(member, roomError(http.StatusBadRequest, err.Error(), time.Now()))
+		return
+	}
+
+	response, err := r.server.client.GetPlayerResponse(request.Context(), message.VideoId)
+	if err == nil {
+		err = response.PlayabilityStatus.Err()
+	}
+	if err != nil {
+		status := http.StatusBadGateway
+		var playabilityErr *PlayabilityError
+		if errors.As(err, &playabilityErr) {
+			status = http.StatusUnprocessableEntity
+		}
+		r.reply(member, roomError(status, err.Error(), time.Now()))
+		return
+	}
+	descriptor := r.server.newDescriptor(request.Context(), r.server.baseURL(request), message.VideoId, response, selector)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.loads++
+	r.videoID, r.selector, r.descriptor, r.request = message.VideoId, selector, descriptor, request
+	r.position, r.playing, r.updatedAt = 0, false, now
+	r.scheduleRefresh(now)
+	r.changed(now, nil)
+}
+
+// scheduleRefresh resolves the video again before its URLs expire. The room lock must be
+// held.
+func (r *room) scheduleRefresh(now time.Time) {
+	if r.refresh != nil {
+		r.refresh.Stop()
+	}
+	if r.closed || r.descriptor.ExpiresAt == nil {
+		return
+	}
+
+	load, previous := r.loads, r.descriptor.ExpiresAt
+	r.refresh = time.AfterFunc(watchRefreshDelay(*previous, now), func() {
+		r.refreshDescriptor(load, previous)
+	})
+}
+
+func (r *room) refreshDescriptor(load int, previous *time.Time) {
+	r.mu.Lock()
+	videoID, selector, request := r.videoID, r.selector, r.request
+	current := r.loads == load && !r.closed
+	r.mu.Unlock()
+	if !current {
+		return
+	}
+
+	descriptor, err := r.server.watchDescriptor(context.Background(), request, videoID, selector, previous)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.loads != load || r.closed {
+		return
+	}
+	if err != nil {
+		r.server.client.log(request.Context(), LevelWarn, "failed to refresh the room video", "room", r.id, "videoId", videoID, "error", err)
+		r.refresh = time.AfterFunc(watchRetryDelay, func() {
+			r.refreshDescriptor(load, previous)
+		})
+		return
+	}
+
+	now := time.Now()
+	r.descriptor = descriptor
+	r.scheduleRefresh(now)
+	r.changed(now, nil)
+}
+
+// changed bumps the version of the state and broadcasts it to the members but the
+// excluded one. The room lock must be held.
+func (r *room) changed(now time.Time, except *roomMember) {
+	r.version++
+	message := RoomMessage{Type: "state", State: r.state(now), ServerTime: now.UnixMilli()}
+	for _, member := range r.members {
+		if member != except {
+			member.push(message)
+		}
+	}
+}
+
+// reply sends the message to the member.
+func (r *room) reply(member *roomMember, message RoomMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.member(member.id) != nil {
+		member.push(message)
+	}
+}
+
+func (r *room) state(now time.Time) *RoomState {
+	state := &RoomState{
+		RoomId:     r.id,
+		Version:    r.version,
+		HostId:     r.hostID,
+		Open:       r.open,
+		Members:    make([]RoomMember, 0, len(r.members)),
+		VideoId:    r.videoID,
+		Descriptor: r.descriptor,
+		Playing:    r.playing,
+		Position:   r.position,
+		UpdatedAt:  r.updatedAt.UnixMilli(),
+	}
+	if r.updatedAt.IsZero() {
+		state.UpdatedAt = now.UnixMilli()
+	}
+	for _, member := range r.members {
+		state.Members = append(state.Members, RoomMember{Id: member.id, Name: member.name})
+	}
+	return state
+}
+
+func (r *room) member(id string) *roomMember {
+	for _, member := range r.members {
+		if member.id == id {
+			return member
+		}
+	}
+	return nil
+}
+
+func (r *room) mayControl(member *roomMember) bool {
+	return r.open || member.id == r.hostID
+}
+
+// positionAt returns the position of the room at the time.
+func (r *room) positionAt(at time.Time) float64 {
+	position := r.position
+	if r.playing {
+		position += at.Sub(r.updatedAt).Seconds()
+	}
+	return r.clamp(position)
+}
+
+// clamp bounds the position to the duration of the video, live videos have none.
+func (r *room) clamp(position float64) float64 {
+	if r.descriptor != nil && r.descriptor.DurationSeconds > 0 {
+		position = math.Min(position, float64(r.descriptor.DurationSeconds))
+	}
+	return math.Max(position, 0)
+}
+
+// driftHint compares the position the member reported at the time with the room. A
+// playing member is told to play slightly faster or slower to catch up smoothly, and to
+// seek once too far off.
+func (r *room) driftHint(position float64, at time.Time, now time.Time) *DriftHint {
+	hint := &DriftHint{Drift: position - r.positionAt(at), Action: "none", Position: r.positionAt(now)}
+	switch drift := math.Abs(hint.Drift); {
+	case drift <= roomDriftTolerance:
+	case drift >= roomSeekDrift || !r.playing:
+		hint.Action = "seek"
+	default:
+		hint.Action = "rate"
+		hint.Rate = 1 - math.Max(-roomMaxRateAdjustment, math.Min(roomMaxRateAdjustment, hint.Drift/10))
+	}
+	return hint
+}
+
+func roomError(status int, message string, now time.Time) RoomMessage {
+	return RoomMessage{Type: "error", Error: &ErrorBody{Code: status, Message: message}, ServerTime: now.UnixMilli()}
+}
@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// JSONSchema generates the JSON Schema of the value type from its Go type and json tags.
+// Named struct types are emitted once under $defs and referenced from their uses.
+func JSONSchema(value interface{}) map[string]interface{} {
+	definitions := map[string]interface{}{}
+	schema := schemaOf(reflect.TypeOf(value), definitions)
+
+	root := map[string]interface{}{
+		"$schema": jsonSchemaDraft,
+	}
+	for key, value := range schema {
+		root[key] = value
+	}
+	if len(definitions) > 0 {
+		root["$defs"] = definitions
+	}
+
+	return root
+}
+
+// DescriptorSchema returns the published JSON Schema of the Descriptor.
+func DescriptorSchema() map[string]interface{} {
+	schema := JSONSchema(Descriptor{})
+	schema["title"] = "Media descriptor"
+	schema["$comment"] = "version " + strconv.Itoa(DescriptorVersion)
+	return schema
+}
+
+func schemaOf(t reflect.Type, definitions map[string]interface{}) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem(), definitions)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaOf(t.Elem(), definitions)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, definitions)
+		}
+		if _, ok := definitions[t.Name()]; !ok {
+			// Reserve the name first so recursive types terminate.
+			definitions[t.Name()] = map[string]interface{}{}
+			definitions[t.Name()] = structSchema(t, definitions)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+	}
+
+	return map[string]interface{}{}
+}
+
+func structSchema(t reflect.Type, definitions map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = schemaOf(field.Type, definitions)
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
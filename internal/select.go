@@ -0,0 +1,242 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrNoStreams = errors.New("no streams matching the selection")
+
+// Selector describes which adaptive streams should be picked from the streaming data.
+type Selector struct {
+	// Audio is the preferred audio layout, falling back to stereo when unavailable.
+	Audio AudioLayout `json:"audio,omitempty"`
+	// MaxHeight limits the video resolution, 0 means no limit.
+	MaxHeight int `json:"maxHeight,omitempty"`
+	// ExcludeHDR skips HDR video for devices that can't tone-map.
+	ExcludeHDR bool `json:"excludeHdr,omitempty"`
+	// Language is the preferred audio track language, falling back to the default track.
+	Language string `json:"language,omitempty"`
+	// Descriptive prefers audio description tracks over regular ones, in the preferred
+	// language or without one in the language of the default track.
+	Descriptive bool `json:"descriptive,omitempty"`
+}
+
+// Selection is the pair of adaptive streams chosen by a Selector.
+type Selection struct {
+	Video *AdaptiveFormat `json:"video,omitempty"`
+	Audio *AdaptiveFormat `json:"audio,omitempty"`
+}
+
+// ParseSelector parses a comma-separated list of key=value selection criteria,
+// e.g. "audio=spatial,maxHeight=1080,hdr=false,lang=de".
+func ParseSelector(spec string) (Selector, error) {
+	selector := Selector{}
+	for _, criterion := range strings.Split(spec, ",") {
+		criterion = strings.TrimSpace(criterion)
+		if criterion == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(criterion, "=")
+		if !found {
+			return selector, fmt.Errorf("invalid selection criterion: %s", criterion)
+		}
+
+		switch key {
+		case "audio":
+			layout, ok := ParseAudioLayout(value)
+			if !ok {
+				return selector, fmt.Errorf("unknown audio layout: %s", value)
+			}
+			selector.Audio = layout
+		case "maxHeight":
+			height, err := strconv.Atoi(value)
+			if err != nil || height < 0 {
+				return selector, fmt.Errorf("invalid max height: %s", value)
+			}
+			selector.MaxHeight = height
+		case "hdr":
+			allowed, err := strconv.ParseBool(value)
+			if err != nil {
+				return selector, fmt.Errorf("invalid hdr flag: %s", value)
+			}
+			selector.ExcludeHDR = !allowed
+		case "lang":
+			selector.Language = value
+		case "descriptive":
+			descriptive, err := strconv.ParseBool(value)
+			if err != nil {
+				return selector, fmt.Errorf("invalid descriptive flag: %s", value)
+			}
+			selector.Descriptive = descriptive
+		default:
+			return selector, fmt.Errorf("unknown selection criterion: %s", key)
+		}
+	}
+
+	return selector, nil
+}
+
+// Select picks the best video and audio streams matching the selector.
+func (s Selector) Select(data StreamingData) (*Selection, error) {
+	selection := &Selection{
+		Video: s.selectVideo(data.AdaptiveFormats),
+		Audio: s.selectAudio(data.AdaptiveFormats),
+	}
+
+	if selection.Video == nil && selection.Audio == nil {
+		return nil, ErrNoStreams
+	}
+
+	return selection, nil
+}
+
+func (s Selector) selectVideo(formats []AdaptiveFormat) *AdaptiveFormat {
+	var best *AdaptiveFormat
+	for i := range formats {
+		format := &formats[i]
+		if !format.IsVideo() {
+			continue
+		}
+		if s.MaxHeight > 0 && format.Height > s.MaxHeight {
+			continue
+		}
+		if s.ExcludeHDR && format.ColorInfo.DynamicRange().IsHDR() {
+			continue
+		}
+		if best == nil || betterVideo(format, best) {
+			best = format
+		}
+	}
+	return best
+}
+
+// selectMuxed picks the best muxed format within the video constraints of the selector.
+func (s Selector) selectMuxed(formats []Format) *Format {
+	var best *Format
+	for i := range formats {
+		format := &formats[i]
+		if s.MaxHeight > 0 && format.Height > s.MaxHeight {
+			continue
+		}
+		if s.ExcludeHDR && format.ColorInfo.DynamicRange().IsHDR() {
+			continue
+		}
+		if best == nil || format.Height > best.Height || (format.Height == best.Height && format.Bitrate > best.Bitrate) {
+			best = format
+		}
+	}
+	return best
+}
+
+func betterVideo(a, b *AdaptiveFormat) bool {
+	if a.Height != b.Height {
+		return a.Height > b.Height
+	}
+	if a.FPS != b.FPS {
+		return a.FPS > b.FPS
+	}
+	return a.Bitrate > b.Bitrate
+}
+
+func (s Selector) selectAudio(formats []AdaptiveFormat) *AdaptiveFormat {
+	preferred := s.Audio
+	if preferred == AudioLayoutNone {
+		preferred = AudioLayoutStereo
+	}
+
+	formats = s.audioTracks(formats)
+
+	for _, layout := range preferred.fallbacks() {
+		if best := bestAudio(formats, func(format *AdaptiveFormat) bool {
+			return format.AudioLayout() == layout
+		}); best != nil {
+			return best
+		}
+	}
+
+	// Nothing at or below the preferred layout, take whatever audio is there.
+	return bestAudio(formats, func(format *AdaptiveFormat) bool {
+		return true
+	})
+}
+
+func bestAudio(formats []AdaptiveFormat, match func(format *AdaptiveFormat) bool) *AdaptiveFormat {
+	var best *AdaptiveFormat
+	for i := range formats {
+		format := &formats[i]
+		if !format.IsAudio() || !match(format) {
+			continue
+		}
+		if best == nil || format.Bitrate > best.Bitrate {
+			best = format
+		}
+	}
+	return best
+}
+
+// audioTracks narrows the audio formats down to a single audio track: the requested
+// language if available, otherwise the default track, or the original track when none
+// is marked default. A descriptive track without a requested language is looked for in
+// the language of the default track. Formats without track info are returned as is,
+// since such videos only have one track.
+func (s Selector) audioTracks(formats []AdaptiveFormat) []AdaptiveFormat {
+	language := s.Language
+	if language == "" && s.Descriptive {
+		language = defaultAudioLanguage(formats)
+	}
+
+	var requested, alternative, defaults, originals []AdaptiveFormat
+	for _, format := range formats {
+		if !format.IsAudio() || format.AudioTrack == nil {
+			continue
+		}
+		track := format.AudioTrack
+		if language != "" && track.MatchesLanguage(language) {
+			if track.IsDescriptive() == s.Descriptive {
+				requested = append(requested, format)
+			} else {
+				alternative = append(alternative, format)
+			}
+		}
+		if track.AudioIsDefault {
+			defaults = append(defaults, format)
+		}
+		if track.IsOriginal() {
+			originals = append(originals, format)
+		}
+	}
+
+	switch {
+	case len(requested) > 0:
+		return requested
+	case len(alternative) > 0:
+		return alternative
+	case len(defaults) > 0:
+		return defaults
+	case len(originals) > 0:
+		return originals
+	}
+	return formats
+}
+
+// defaultAudioLanguage returns the language of the default audio track, or of the
+// original track when none is marked default.
+func defaultAudioLanguage(formats []AdaptiveFormat) string {
+	language := ""
+	for _, format := range formats {
+		if !format.IsAudio() || format.AudioTrack == nil {
+			continue
+		}
+		if format.AudioTrack.AudioIsDefault {
+			return format.AudioTrack.Language()
+		}
+		if language == "" && format.AudioTrack.IsOriginal() {
+			language = format.AudioTrack.Language()
+		}
+	}
+	return language
+}
@@ -0,0 +1,226 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Server is the HTTP API resolving videos for clients.
+type Server struct {
+	// ProxyMedia points the descriptor streams to the stream endpoint of the server
+	// instead of googlevideo.
+	ProxyMedia bool
+	// Tokens signs the stream URLs of the descriptors and is required by the stream
+	// endpoint, nil leaves the stream endpoint open.
+	Tokens *StreamTokens
+	// Auth authenticates the callers and enforces their limits, nil serves everyone.
+	// The stream endpoint is authorized by its tokens instead when Tokens is set.
+	Auth *Authenticator
+	// PublicURL is the base URL the clients reach the server at, e.g. https://helper.example.com,
+	// for the stream URLs of the descriptors. Empty derives it from the HTTP requests, the
+	// gRPC API then requires it to proxy the media.
+	PublicURL string
+	// AllowedOrigins are the origins, such as https://world.example.com, of the pages
+	// allowed to open the WebSocket endpoints besides the pages of the server itself, *
+	// allows any. Native clients send no origin and are always allowed.
+	AllowedOrigins []string
+
+	client *Client
+	mux    *http.ServeMux
+	media  mediaURLCache
+	rooms  roomHub
+}
+
+// ErrorEnvelope is the body of every error response of the server.
+type ErrorEnvelope struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewServer returns a server resolving videos with the client.
+func NewServer(client *Client) *Server {
+	server := &Server{client: client, mux: http.NewServeMux()}
+	server.mux.HandleFunc("/v1/resolve", server.handleResolve)
+	server.mux.HandleFunc("/v1/schema", server.handleSchema)
+	server.mux.HandleFunc("/v1/stream", server.handleStream)
+	server.mux.HandleFunc("/v1/manifest", server.handleManifest)
+	server.mux.HandleFunc("/v1/watch", server.handleWatch)
+	server.mux.HandleFunc("/v1/room", server.handleRoom)
+	server.mux.Handle("/metrics", Metrics)
+	return server
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Unknown paths share a label so they can't blow up the metric cardinality.
+	handler := "other"
+	if _, pattern := s.mux.Handler(r); pattern != "" {
+		handler = pattern
+	}
+
+	// The request ID of a caller or a proxy in front is kept so the logs can be joined.
+	requestID := r.Header.Get("X-Request-Id")
+	if requestID == "" || len(requestID) > 128 {
+		requestID = newRequestID()
+	}
+	w.Header().Set("X-Request-Id", requestID)
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	ctx, span := tracer.Start(ContextWithRequestID(ctx, requestID), r.Method+" "+handler,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("http.method", r.Method), attribute.String("http.route", handler)))
+	r = r.WithContext(ctx)
+
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	start := time.Now()
+	defer func() {
+		httpDuration.ObserveSince(start, handler)
+		httpRequests.Inc(handler, strconv.Itoa(recorder.status))
+		span.SetAttributes(attribute.Int("http.status_code", recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+		span.End()
+		s.client.log(ctx, LevelInfo, "request served", "method", r.Method, "path", r.URL.Path,
+			"status", recorder.status, "duration", time.Since(start))
+	}()
+	w = recorder
+
+	streamWithToken := r.URL.Path == "/v1/stream" && s.Tokens != nil
+	if s.Auth != nil && !streamWithToken && !s.Auth.authorize(w, r) {
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// statusRecorder records the status of the response for the metrics.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack lets the WebSocket endpoints take over the connection.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if err == nil {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ErrorEnvelope{Error: ErrorBody{Code: status, Message: message}})
+}
+
+// writeUpstreamError reports a failed upstream request: 422 for an unplayable video, 503
+// with Retry-After while the circuit breaker is open, 502 otherwise.
+func writeUpstreamError(w http.ResponseWriter, err error) {
+	var playabilityErr *PlayabilityError
+	if errors.As(err, &playabilityErr) {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	var circuitErr *CircuitOpenError
+	if errors.As(err, &circuitErr) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(circuitErr.RetryAfter.Seconds()))))
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	writeError(w, http.StatusBadGateway, err.Error())
+}
+
+// handleResolve resolves the video from the videoId query parameter. The streams are
+// chosen with the select parameter using the yt --select syntax, and the output parameter
+// switches between the descriptor (default) and the raw player response.
+func (s *Server) handleResolve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	videoId := query.Get("videoId")
+	if videoId == "" {
+		writeError(w, http.StatusBadRequest, "missing videoId")
+		return
+	}
+
+	selector, err := ParseSelector(query.Get("select"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	output := query.Get("output")
+	if output != "" && output != "descriptor" && output != "raw" {
+		writeError(w, http.StatusBadRequest, "unknown output: "+output)
+		return
+	}
+
+	response, err := s.client.GetPlayerResponse(r.Context(), videoId)
+	if err != nil {
+		writeUpstreamError(w, err)
+		return
+	}
+
+	if output == "raw" {
+		writeJSON(w, http.StatusOK, response)
+		return
+	}
+	if err := response.PlayabilityStatus.Err(); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.newDescriptor(r.Context(), s.baseURL(r), videoId, response, selector))
+}
+
+// newDescriptor builds the descriptor of the player response of the video, pointing its
+// streams to the stream endpoint of the server at the base URL when proxying the media.
+func (s *Server) newDescriptor(ctx context.Context, baseURL, videoID string, response *PlayerResponse, selector Selector) *Descriptor {
+	_, span := tracer.Start(ctx, "select streams")
+	defer span.End()
+
+	now := time.Now()
+	descriptor := NewDescriptor(videoID, response, selector, now)
+	if s.ProxyMedia {
+		s.proxyDescriptor(baseURL, descriptor, now)
+	}
+	return descriptor
+}
+
+func (s *Server) handleSchema(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, DescriptorSchema())
+}
@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Session is the identity YT assigned to the client: the visitor data and the cookies.
+type Session struct {
+	VisitorData string         `json:"visitorData,omitempty"`
+	Cookies     []*http.Cookie `json:"cookies,omitempty"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	Requests    int            `json:"requests"`
+}
+
+// SessionStore keeps the session of the client across requests, and optionally across
+// runs in a file, as consistent sessions get fewer bot checks. The session is rotated
+// after MaxRequests requests, after MaxAge, and when YT asks to sign in.
+type SessionStore struct {
+	// Path is the file the session is persisted to, empty keeps it in memory.
+	Path string
+	// MaxRequests rotates the session after that many requests, 0 never.
+	MaxRequests int
+	// MaxAge rotates the session once it is that old, 0 never.
+	MaxAge time.Duration
+
+	mu      sync.Mutex
+	session Session
+}
+
+// NewSessionStore returns a store loading the session from the file if it exists.
+func NewSessionStore(path string) (*SessionStore, error) {
+	store := &SessionStore{Path: path, session: Session{CreatedAt: time.Now()}}
+	if path == "" {
+		return store, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &store.session); err != nil {
+		return nil, fmt.Errorf("failed to parse session %s: %v", path, err)
+	}
+	return store, nil
+}
+
+// Session returns a copy of the current session.
+func (s *SessionStore) Session() Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session := s.session
+	session.Cookies = append([]*http.Cookie(nil), s.session.Cookies...)
+	return session
+}
+
+// Rotate drops the current session, the next request starts a new one.
+func (s *SessionStore) Rotate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.session = Session{CreatedAt: time.Now()}
+	return s.save()
+}
+
+// begin returns the visitor data and the cookies to send with the next request,
+// rotating the session first if it is used up.
+func (s *SessionStore) begin(now time.Time) (string, []*http.Cookie) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expired := s.MaxAge > 0 && now.Sub(s.session.CreatedAt) > s.MaxAge
+	if expired || (s.MaxRequests > 0 && s.session.Requests >= s.MaxRequests) {
+		s.session = Session{CreatedAt: now}
+	}
+
+	s.session.Requests++
+	return s.session.VisitorData, append([]*http.Cookie(nil), s.session.Cookies...)
+}
+
+// update stores the visitor data and the cookies YT answered with.
+func (s *SessionStore) update(visitorData string, cookies []*http.Cookie) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := false
+	if visitorData != "" && visitorData != s.session.VisitorData {
+		s.session.VisitorData = visitorData
+		changed = true
+	}
+
+	for _, cookie := range cookies {
+		kept := s.session.Cookies[:0]
+		for _, existing := range s.session.Cookies {
+			if existing.Name != cookie.Name {
+				kept = append(kept, existing)
+			}
+		}
+		s.session.Cookies = kept
+		if cookie.MaxAge >= 0 {
+			s.session.Cookies = append(s.session.Cookies, &http.Cookie{Name: cookie.Name, Value: cookie.Value})
+		}
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+	return s.save()
+}
+
+// save writes the session to the file, the lock must be held.
+func (s *SessionStore) save() error {
+	if s.Path == "" {
+		return nil
+	}
+
+	content, err := json.MarshalIndent(s.session, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write then rename so concurrent runs never read a partial file.
+	temp := s.Path + ".tmp"
+	if err := os.WriteFile(temp, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(temp, s.Path)
+}
@@ -0,0 +1,350 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// mediaURLExpiryMargin refreshes the media URLs that early, so a stream started right
+// before the expiry does not fail midway.
+const mediaURLExpiryMargin = time.Minute
+
+// streamResponseHeaders are passed from the media response to the client.
+var streamResponseHeaders = []string{
+	"Accept-Ranges",
+	"Content-Length",
+	"Content-Range",
+	"Content-Type",
+	"ETag",
+	"Last-Modified",
+}
+
+var errMediaExpired = errors.New("media URL expired")
+
+// mediaURLs are the media URLs of the formats of a resolved video by itag.
+type mediaURLs struct {
+	urls      map[int]string
+	expiresAt time.Time
+}
+
+// mediaURLCache keeps the media URLs of the streamed videos until they expire, so every
+// Range request of a player does not resolve the video again.
+type mediaURLCache struct {
+	mu     sync.Mutex
+	videos map[string]*mediaURLs
+}
+
+func (c *mediaURLCache) get(videoID string, now time.Time) *mediaURLs {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	media, found := c.videos[videoID]
+	if !found || now.After(media.expiresAt) {
+		return nil
+	}
+	return media
+}
+
+func (c *mediaURLCache) put(videoID string, media *mediaURLs, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.videos == nil {
+		c.videos = map[string]*mediaURLs{}
+	}
+	for id, cached := range c.videos {
+		if now.After(cached.expiresAt) {
+			delete(c.videos, id)
+		}
+	}
+	c.videos[videoID] = media
+}
+
+func (c *mediaURLCache) invalidate(videoID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.videos, videoID)
+}
+
+func newMediaURLs(streamingData StreamingData, now time.Time) *mediaURLs {
+	media := &mediaURLs{urls: map[int]string{}, expiresAt: now.Add(stickyProxyTTL)}
+	if expiresIn, err := strconv.Atoi(streamingData.ExpiresInSeconds); err == nil {
+		media.expiresAt = now.Add(time.Duration(expiresIn) * time.Second)
+	}
+	media.expiresAt = media.expiresAt.Add(-mediaURLExpiryMargin)
+
+	for _, format := range streamingData.Formats {
+		media.urls[format.Itag] = format.URL
+	}
+	for _, format := range streamingData.AdaptiveFormats {
+		media.urls[format.Itag] = format.URL
+	}
+	return media
+}
+
+// mediaURL returns the media URL of the format, resolving the video unless cached.
+func (s *Server) mediaURL(ctx context.Context, videoID string, itag int) (string, error) {
+	now := time.Now()
+	media := s.media.get(videoID, now)
+	if media == nil {
+		response, err := s.client.GetPlayerResponse(ctx, videoID)
+		if err != nil {
+			return "", err
+		}
+		if err := response.PlayabilityStatus.Err(); err != nil {
+			return "", err
+		}
+		media = newMediaURLs(response.StreamingData, now)
+		s.media.put(videoID, media, now)
+	}
+
+	mediaURL, found := media.urls[itag]
+	if !found || mediaURL == "" {
+		return "", nil
+	}
+	return mediaURL, nil
+}
+
+// baseURL returns the base URL the client of the request reaches the server at.
+func (s *Server) baseURL(r *http.Request) string {
+	if s.PublicURL != "" {
+		return s.PublicURL
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if forwarded := r.Header.Get("X-Forwarded-Proto"); forwarded != "" {
+		scheme = forwarded
+	}
+	return scheme + "://" + r.Host
+}
+
+// streamURL returns the URL of the format on the stream endpoint of the server at the
+// base URL.
+func streamURL(baseURL, videoID string, itag int, token string) string {
+	query := url.Values{}
+	query.Set("videoId", videoID)
+	query.Set("itag", strconv.Itoa(itag))
+	if token != "" {
+		query.Set("token", token)
+	}
+	return strings.TrimSuffix(baseURL, "/") + "/v1/stream?" + query.Encode()
+}
+
+// proxyDescriptor points the streams of the descriptor to the stream endpoint at the base
+// URL, with tokens if the server requires them. The descriptor then expires with the tokens.
+func (s *Server) proxyDescriptor(baseURL string, descriptor *Descriptor, now time.Time) {
+	for _, stream := range []*DescriptorStream{descriptor.Streams.Video, descriptor.Streams.Audio, descriptor.Streams.Muxed} {
+		if stream == nil {
+			continue
+		}
+
+		token := ""
+		if s.Tokens != nil {
+			var expiresAt time.Time
+			token, expiresAt = s.Tokens.Issue(descriptor.Id, stream.Itag, now)
+			if descriptor.ExpiresAt == nil || expiresAt.Before(*descriptor.ExpiresAt) {
+				expiresAt = expiresAt.UTC()
+				descriptor.ExpiresAt = &expiresAt
+			}
+		}
+		stream.URL = streamURL(baseURL, descriptor.Id, stream.Itag, token)
+	}
+}
+
+// handleStream streams the media of the videoId and itag query parameters from
+// googlevideo, passing Range requests through. The media URL is resolved again when it
+// has expired or googlevideo refuses it. When the server has stream keys, the token
+// parameter must be a token issued for the format.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Expose-Headers", "Accept-Ranges, Content-Length, Content-Range")
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodOptions:
+		w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD")
+		w.Header().Set("Access-Control-Allow-Headers", "Range")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	videoId := query.Get("videoId")
+	if videoId == "" {
+		writeError(w, http.StatusBadRequest, "missing videoId")
+		return
+	}
+	itag, err := strconv.Atoi(query.Get("itag"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid itag")
+		return
+	}
+
+	if s.Tokens != nil {
+		if err := s.Tokens.Verify(query.Get("token"), videoId, itag, time.Now()); err != nil {
+			writeError(w, http.StatusForbidden, err.Error())
+			return
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		mediaURL, err := s.mediaURL(r.Context(), videoId, itag)
+		if err != nil {
+			writeUpstreamError(w, err)
+			return
+		}
+		if mediaURL == "" {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("no format with itag %d", itag))
+			return
+		}
+
+		err = s.streamMedia(w, r, videoId, mediaURL)
+		if errors.Is(err, errMediaExpired) && attempt == 1 {
+			s.client.log(r.Context(), LevelInfo, "refreshing the media URLs", "videoId", videoId, "error", err)
+			s.media.invalidate(videoId)
+			if err := s.client.InvalidatePlayerResponse(r.Context(), videoId); err != nil {
+				s.client.log(r.Context(), LevelWarn, "failed to invalidate the player response", "videoId", videoId, "error", err)
+			}
+			continue
+		}
+		if err != nil {
+			writeUpstreamError(w, err)
+		}
+		return
+	}
+}
+
+// streamMedia copies the media response to the client. It returns errMediaExpired
+// before writing anything if googlevideo refuses the URL, and nil once the response
+// started, as a broken stream can't be reported any more.
+func (s *Server) streamMedia(w http.ResponseWriter, r *http.Request, videoID string, mediaURL string) (err error) {
+	ctx, span := tracer.Start(r.Context(), "stream media", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("video.id", videoID)))
+	defer func() { endSpan(span, err) }()
+
+	// Media requests go through the proxy the video was resolved from, and are cancelled
+	// when the client goes away.
+	request, err := http.NewRequestWithContext(ContextWithMedia(ctx, videoID), r.Method, mediaURL, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("User-Agent", s.client.Profile.UserAgent)
+	// Transparent decompression would break the byte ranges.
+	request.Header.Set("Accept-Encoding", "identity")
+	for _, header := range []string{"Range", "If-Range"} {
+		if value := r.Header.Get(header); value != "" {
+			request.Header.Set(header, value)
+		}
+	}
+
+	response, err := s.client.HTTPClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusPartialContent, http.StatusNotModified, http.StatusRequestedRangeNotSatisfiable:
+	case http.StatusForbidden, http.StatusGone:
+		_, _ = io.Copy(io.Discard, response.Body)
+		return errMediaExpired
+	default:
+		_, _ = io.Copy(io.Discard, response.Body)
+		return newStatusError(response, time.Now())
+	}
+	span.SetAttributes(attribute.Int("http.status_code", response.StatusCode))
+
+	for _, header := range streamResponseHeaders {
+		if value := response.Header.Get(header); value != "" {
+			w.Header().Set(header, value)
+		}
+	}
+	w.WriteHeader(response.StatusCode)
+
+	if r.Method == http.MethodHead {
+		return nil
+	}
+
+	// Writes block while the client is slow to read, which stops reading from googlevideo
+	// in turn, so a stream holds a single copy buffer however slow the client.
+	written, err := io.Copy(w, response.Body)
+	streamedBytes.Add(float64(written))
+	span.SetAttributes(attribute.Int64("stream.bytes", written))
+	if err != nil {
+		s.client.log(ctx, LevelInfo, "streaming interrupted", "videoId", videoID, "bytes", written, "error", err)
+	}
+	return nil
+}
+
+// manifestContentTypes are the content types of the manifests by type.
+var manifestContentTypes = map[string]string{
+	"hls":  "application/vnd.apple.mpegurl",
+	"dash": "application/dash+xml",
+}
+
+// handleManifest returns the HLS or DASH manifest of the videoId query parameter, of live
+// streams and premieres, as chosen by the type parameter. Manifests are shared through
+// the cache of the client until the media URLs of the video expire.
+func (s *Server) handleManifest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	videoId := query.Get("videoId")
+	if videoId == "" {
+		writeError(w, http.StatusBadRequest, "missing videoId")
+		return
+	}
+	manifestType := query.Get("type")
+	contentType, found := manifestContentTypes[manifestType]
+	if !found {
+		writeError(w, http.StatusBadRequest, "unknown manifest type: "+manifestType)
+		return
+	}
+
+	response, err := s.client.GetPlayerResponse(r.Context(), videoId)
+	if err != nil {
+		writeUpstreamError(w, err)
+		return
+	}
+	if err := response.PlayabilityStatus.Err(); err != nil {
+		writeUpstreamError(w, err)
+		return
+	}
+
+	manifestURL := response.StreamingData.HlsManifestUrl
+	if manifestType == "dash" {
+		manifestURL = response.StreamingData.DashManifestUrl
+	}
+	if manifestURL == "" {
+		writeError(w, http.StatusNotFound, "no "+manifestType+" manifest")
+		return
+	}
+
+	manifest, err := s.client.FetchArtifact(r.Context(), videoId, manifestURL, playerResponseTTL(response))
+	if err != nil {
+		writeUpstreamError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(manifest)
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid stream token")
+	ErrTokenExpired = errors.New("stream token expired")
+)
+
+// StreamKey is a secret signing the stream tokens, identified in the tokens by its Id.
+type StreamKey struct {
+	Id     string
+	Secret []byte
+}
+
+// ParseStreamKey parses a key given as <id>:<secret>.
+func ParseStreamKey(value string) (StreamKey, error) {
+	id, secret, found := strings.Cut(value, ":")
+	if !found || id == "" || secret == "" {
+		return StreamKey{}, fmt.Errorf("invalid stream key, expected <id>:<secret>")
+	}
+	if strings.Contains(id, ".") {
+		return StreamKey{}, fmt.Errorf("invalid stream key id %s: must not contain dots", id)
+	}
+	return StreamKey{Id: id, Secret: []byte(secret)}, nil
+}
+
+// StreamTokens issues and verifies the HMAC-SHA256 signed tokens authorizing the stream
+// of a format of a video until they expire, so the stream endpoint is not an open relay.
+// Keys are rotated by adding the new key first and removing the old one once the tokens
+// it signed have expired.
+type StreamTokens struct {
+	// Keys verify the tokens, the first one signs them.
+	Keys []StreamKey
+	// TTL is the lifetime of the tokens.
+	TTL time.Duration
+}
+
+// Issue returns the token of the format of the video and its expiry.
+func (t *StreamTokens) Issue(videoID string, itag int, now time.Time) (string, time.Time) {
+	key := t.Keys[0]
+	expiresAt := now.Add(t.TTL).Truncate(time.Second)
+	payload := strings.Join([]string{key.Id, videoID, strconv.Itoa(itag), strconv.FormatInt(expiresAt.Unix(), 10)}, ".")
+	return payload + "." + sign(key.Secret, payload), expiresAt
+}
+
+// Verify checks the token was issued for the format of the video and has not expired.
+func (t *StreamTokens) Verify(token string, videoID string, itag int, now time.Time) error {
+	fields := strings.Split(token, ".")
+	if len(fields) != 5 {
+		return ErrInvalidToken
+	}
+
+	var key *StreamKey
+	for i := range t.Keys {
+		if t.Keys[i].Id == fields[0] {
+			key = &t.Keys[i]
+			break
+		}
+	}
+	if key == nil {
+		return ErrInvalidToken
+	}
+
+	payload := strings.Join(fields[:4], ".")
+	if !hmac.Equal([]byte(fields[4]), []byte(sign(key.Secret, payload))) {
+		return ErrInvalidToken
+	}
+
+	if fields[1] != videoID || fields[2] != strconv.Itoa(itag) {
+		return ErrInvalidToken
+	}
+
+	expires, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	if now.Unix() > expires {
+		return ErrTokenExpired
+	}
+	return nil
+}
+
+func sign(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseStreamKey(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    StreamKey
+		wantErr bool
+	}{
+		{value: "k1:secret", want: StreamKey{Id: "k1", Secret: []byte("secret")}},
+		{value: "k1:sec:ret", want: StreamKey{Id: "k1", Secret: []byte("sec:ret")}},
+		{value: "k1", wantErr: true},
+		{value: ":secret", wantErr: true},
+		{value: "k1:", wantErr: true},
+		{value: "k.1:secret", wantErr: true},
+	}
+
+	for _, test := range tests {
+		key, err := ParseStreamKey(test.value)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("ParseStreamKey(%q) succeeded, want an error", test.value)
+			}
+			continue
+		}
+		if err != nil || key.Id != test.want.Id || string(key.Secret) != string(test.want.Secret) {
+			t.Errorf("ParseStreamKey(%q) = %+v, %v, want %+v", test.value, key, err, test.want)
+		}
+	}
+}
+
+func TestStreamTokens(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	oldKey := StreamKey{Id: "k1", Secret: []byte("old-secret")}
+	newKey := StreamKey{Id: "k2", Secret: []byte("new-secret")}
+
+	tokens := &StreamTokens{Keys: []StreamKey{oldKey}, TTL: time.Hour}
+	oldToken, expiresAt := tokens.Issue("dQw4w9WgXcQ", 18, now)
+	if want := now.Add(time.Hour); !expiresAt.Equal(want) {
+		t.Errorf("expires at %s, want %s", expiresAt, want)
+	}
+
+	// Rotated: the new key signs, the old one still verifies.
+	tokens.Keys = []StreamKey{newKey, oldKey}
+	newToken, _ := tokens.Issue("dQw4w9WgXcQ", 18, now)
+	if !strings.HasPrefix(newToken, "k2.") {
+		t.Errorf("token %s not signed by the new key", newToken)
+	}
+
+	// replace returns the token with the field replaced, keeping the signature.
+	replace := func(token string, field int, value string) string {
+		fields := strings.Split(token, ".")
+		fields[field] = value
+		return strings.Join(fields, ".")
+	}
+
+	tests := []struct {
+		name    string
+		keys    []StreamKey
+		token   string
+		videoID string
+		itag    int
+		now     time.Time
+		wantErr error
+	}{
+		{name: "valid", token: newToken, videoID: "dQw4w9WgXcQ", itag: 18, now: now},
+		{name: "old key", token: oldToken, videoID: "dQw4w9WgXcQ", itag: 18, now: now},
+		{name: "old key removed", keys: []StreamKey{newKey}, token: oldToken, videoID: "dQw4w9WgXcQ", itag: 18, now: now, wantErr: ErrInvalidToken},
+		{name: "unknown key", token: replace(newToken, 0, "k3"), videoID: "dQw4w9WgXcQ", itag: 18, now: now, wantErr: ErrInvalidToken},
+		{name: "key id swapped", token: replace(newToken, 0, "k1"), videoID: "dQw4w9WgXcQ", itag: 18, now: now, wantErr: ErrInvalidToken},
+		{name: "tampered signature", token: replace(newToken, 4, strings.Repeat("A", 43)), videoID: "dQw4w9WgXcQ", itag: 18, now: now, wantErr: ErrInvalidToken},
+		{name: "missing signature", token: replace(newToken, 4, ""), videoID: "dQw4w9WgXcQ", itag: 18, now: now, wantErr: ErrInvalidToken},
+		{name: "tampered video", token: replace(newToken, 1, "9bZkp7q19f0"), videoID: "9bZkp7q19f0", itag: 18, now: now, wantErr: ErrInvalidToken},
+		{name: "tampered itag", token: replace(newToken, 2, "22"), videoID: "dQw4w9WgXcQ", itag: 22, now: now, wantErr: ErrInvalidToken},
+		{name: "tampered expiry", token: replace(newToken, 3, "9999999999"), videoID: "dQw4w9WgXcQ", itag: 18, now: now, wantErr: ErrInvalidToken},
+		{name: "other video", token: newToken, videoID: "9bZkp7q19f0", itag: 18, now: now, wantErr: ErrInvalidToken},
+		{name: "other itag", token: newToken, videoID: "dQw4w9WgXcQ", itag: 22, now: now, wantErr: ErrInvalidToken},
+		{name: "expires now", token: newToken, videoID: "dQw4w9WgXcQ", itag: 18, now: now.Add(time.Hour)},
+		{name: "expired", token: newToken, videoID: "dQw4w9WgXcQ", itag: 18, now: now.Add(time.Hour + time.Second), wantErr: ErrTokenExpired},
+		{name: "empty", token: "", videoID: "dQw4w9WgXcQ", itag: 18, now: now, wantErr: ErrInvalidToken},
+		{name: "too few parts", token: strings.Join(strings.Split(newToken, ".")[:4], "."), videoID: "dQw4w9WgXcQ", itag: 18, now: now, wantErr: ErrInvalidToken},
+		{name: "too many parts", token: newToken + ".extra", videoID: "dQw4w9WgXcQ", itag: 18, now: now, wantErr: ErrInvalidToken},
+		{name: "non-numeric expiry", token: signedToken(newKey, "k2.dQw4w9WgXcQ.18.soon"), videoID: "dQw4w9WgXcQ", itag: 18, now: now, wantErr: ErrInvalidToken},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			verifier := tokens
+			if test.keys != nil {
+				verifier = &StreamTokens{Keys: test.keys, TTL: tokens.TTL}
+			}
+			err := verifier.Verify(test.token, test.videoID, test.itag, test.now)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Verify(%s) = %v, want %v", test.token, err, test.wantErr)
+			}
+		})
+	}
+}
+
+// signedToken signs an arbitrary payload, which Issue would never produce.
+func signedToken(key StreamKey, payload string) string {
+	return payload + "." + sign(key.Secret, payload)
+}
@@ -0,0 +1,307 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// watchRefreshMargin is how long before the URLs of a watched video expire it is
+	// resolved again, leaving the clients time to switch to the fresh URLs.
+	watchRefreshMargin = 5 * time.Minute
+	// watchRetryDelay is how long to wait before resolving again a watched video that
+	// failed to resolve.
+	watchRetryDelay = 30 * time.Second
+	// watchPingInterval is how often the connections are pinged, a connection silent for
+	// twice that long is closed.
+	watchPingInterval = 30 * time.Second
+	// watchWriteTimeout bounds the writes to a client that stopped reading.
+	watchWriteTimeout = 10 * time.Second
+	// watchMaxSubscriptions bounds the videos watched by a connection.
+	watchMaxSubscriptions = 100
+
+	// websocketSubprotocol is selected when a client offers it. Browsers offer it with the
+	// credential as the bearer.<credential> subprotocol, as they can't set the headers of a
+	// WebSocket handshake, and fail the connection unless the server selects one.
+	websocketSubprotocol = "web-helper"
+	// websocketCredentialPrefix prefixes the credential offered as a subprotocol.
+	websocketCredentialPrefix = "bearer."
+)
+
+// WatchRequest is a message of a client of the watch endpoint.
+type WatchRequest struct {
+	// Type is subscribe or unsubscribe.
+	Type    string `json:"type"`
+	VideoId string `json:"videoId"`
+	// Select chooses the streams of the descriptors with the yt --select syntax.
+	Select string `json:"select,omitempty"`
+}
+
+// WatchMessage is a message pushed to the clients of the watch endpoint: the descriptor
+// of a subscribed video, on subscription then every time its URLs were refreshed, or an
+// error.
+type WatchMessage struct {
+	// Type is descriptor or error.
+	Type       string      `json:"type"`
+	VideoId    string      `json:"videoId,omitempty"`
+	Descriptor *Descriptor `json:"descriptor,omitempty"`
+	Error      *ErrorBody  `json:"error,omitempty"`
+}
+
+// upgradeWebSocket upgrades the request to a WebSocket connection, replying with the
+// error on failure.
+func (s *Server) upgradeWebSocket(w http.ResponseWriter, r *http.Request) (*websocket.Conn, error) {
+	upgrader := websocket.Upgrader{CheckOrigin: s.checkOrigin, Subprotocols: []string{websocketSubprotocol}}
+	return upgrader.Upgrade(w, r, nil)
+}
+
+// checkOrigin accepts the WebSocket handshakes of native clients, which send no Origin,
+// of pages served by the host of the server, and of the AllowedOrigins.
+func (s *Server) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if parsed, err := url.Parse(origin); err == nil && strings.EqualFold(parsed.Host, r.Host) {
+		return true
+	}
+	for _, allowed := range s.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// watchConn is a connection of the watch endpoint and the videos it subscribed to.
+type watchConn struct {
+	server  *Server
+	request *http.Request
+	conn    *websocket.Conn
+
+	writeMu       sync.Mutex
+	mu            sync.Mutex
+	subscriptions map[string]*watchSubscription
+}
+
+type watchSubscription struct {
+	cancel context.CancelFunc
+}
+
+// handleWatch upgrades the request to a WebSocket connection on which the client
+// subscribes to videos. The descriptor of a subscribed video is pushed right away, then
+// again before its URLs expire, so playback never hits an expired URL.
+func (s *Server) handleWatch(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgradeWebSocket(w, r)
+	if err != nil {
+		// The upgrader replied with the error already.
+		return
+	}
+
+	c := &watchConn{server: s, request: r, conn: conn, subscriptions: map[string]*watchSubscription{}}
+	c.serve()
+}
+
+func (c *watchConn) serve() {
+	ctx, cancel := context.WithCancel(c.request.Context())
+	defer cancel()
+	defer c.conn.Close()
+
+	extendDeadline := func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(2 * watchPingInterval))
+	}
+	_ = extendDeadline("")
+	c.conn.SetPongHandler(extendDeadline)
+	go c.ping(ctx)
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			c.server.client.log(ctx, LevelDebug, "watch connection closed", "error", err)
+			return
+		}
+		_ = extendDeadline("")
+
+		var request WatchRequest
+		if err := json.Unmarshal(data, &request); err != nil {
+			c.send(ctx, watchError("", http.StatusBadRequest, "invalid message: "+err.Error()))
+			continue
+		}
+		c.handle(ctx, request)
+	}
+}
+
+func (c *watchConn) handle(ctx context.Context, request WatchRequest) {
+	switch request.Type {
+	case "subscribe":
+		if request.VideoId == "" {
+			c.send(ctx, watchError("", http.StatusBadRequest, "missing videoId"))
+			return
+		}
+		selector, err := ParseSelector(request.Select)
+		if err != nil {
+			c.send(ctx, watchError(request.VideoId, http.StatusBadRequest, err.Error()))
+			return
+		}
+
+		c.mu.Lock()
+		if subscription, found := c.subscriptions[request.VideoId]; found {
+			// Subscribing again changes the selection.
+			subscription.cancel()
+		} else if len(c.subscriptions) >= watchMaxSubscriptions {
+			c.mu.Unlock()
+			c.send(ctx, watchError(request.VideoId, http.StatusTooManyRequests, "too many subscriptions"))
+			return
+		}
+		watchCtx, cancel := context.WithCancel(ctx)
+		subscription := &watchSubscription{cancel: cancel}
+		c.subscriptions[request.VideoId] = subscription
+		c.mu.Unlock()
+
+		c.server.client.log(ctx, LevelDebug, "watching video", "videoId", request.VideoId, "select", request.Select)
+		go func() {
+			c.watch(watchCtx, request.VideoId, selector)
+			c.unsubscribe(request.VideoId, subscription)
+		}()
+	case "unsubscribe":
+		c.mu.Lock()
+		subscription := c.subscriptions[request.VideoId]
+		c.mu.Unlock()
+		if subscription != nil {
+			c.unsubscribe(request.VideoId, subscription)
+		}
+	default:
+		c.send(ctx, watchError(request.VideoId, http.StatusBadRequest, "unknown message type: "+request.Type))
+	}
+}
+
+// unsubscribe cancels the subscription, unless the video was subscribed again since.
+func (c *watchConn) unsubscribe(videoID string, subscription *watchSubscription) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	subscription.cancel()
+	if c.subscriptions[videoID] == subscription {
+		delete(c.subscriptions, videoID)
+	}
+}
+
+// watch pushes the descriptor of the video, then a fresh one before each expiry of its
+// URLs, until the subscription is cancelled.
+func (c *watchConn) watch(ctx context.Context, videoID string, selector Selector) {
+	var previous *time.Time
+	for {
+		var delay time.Duration
+		descriptor, err := c.server.watchDescriptor(ctx, c.request, videoID, selector, previous)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			var circuitErr *CircuitOpenError
+			status := http.StatusBadGateway
+			if errors.As(err, &circuitErr) {
+				status = http.StatusServiceUnavailable
+			}
+			c.send(ctx, watchError(videoID, status, err.Error()))
+			delay = watchRetryDelay
+		default:
+			c.send(ctx, WatchMessage{Type: "descriptor", VideoId: videoID, Descriptor: descriptor})
+			// Without streams, e.g. for an unplayable video, there is nothing to refresh.
+			if descriptor.ExpiresAt == nil {
+				return
+			}
+			previous = descriptor.ExpiresAt
+			delay = watchRefreshDelay(*descriptor.ExpiresAt, time.Now())
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// watchDescriptor resolves the descriptor of a watched video. A descriptor expiring no
+// later than the previous one was built from the cached player response, which is then
+// dropped to resolve the video again. The other watchers of the video get the fresh
+// response from the cache.
+func (s *Server) watchDescriptor(ctx context.Context, r *http.Request, videoID string, selector Selector, previous *time.Time) (*Descriptor, error) {
+	response, err := s.client.GetPlayerResponse(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+	descriptor := s.newDescriptor(ctx, s.baseURL(r), videoID, response, selector)
+	if previous == nil || descriptor.ExpiresAt == nil || descriptor.ExpiresAt.Sub(*previous) > 2*time.Second {
+		return descriptor, nil
+	}
+
+	if err := s.client.InvalidatePlayerResponse(ctx, videoID); err != nil {
+		return nil, err
+	}
+	response, err = s.client.GetPlayerResponse(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+	return s.newDescriptor(ctx, s.baseURL(r), videoID, response, selector), nil
+}
+
+// watchRefreshDelay returns how long to wait before refreshing URLs expiring at
+// expiresAt: until watchRefreshMargin before, or half their lifetime if shorter.
+func watchRefreshDelay(expiresAt time.Time, now time.Time) time.Duration {
+	lifetime := expiresAt.Sub(now)
+	delay := lifetime - watchRefreshMargin
+	if delay < lifetime/2 {
+		delay = lifetime / 2
+	}
+	if delay < time.Second {
+		delay = time.Second
+	}
+	return delay
+}
+
+func watchError(videoID string, status int, message string) WatchMessage {
+	return WatchMessage{Type: "error", VideoId: videoID, Error: &ErrorBody{Code: status, Message: message}}
+}
+
+// send writes the message unless the subscription it belongs to was cancelled. A failed
+// write closes the connection, which stops the read loop.
+func (c *watchConn) send(ctx context.Context, message WatchMessage) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if ctx.Err() != nil {
+		return
+	}
+	_ = c.conn.SetWriteDeadline(time.Now().Add(watchWriteTimeout))
+	if err := c.conn.WriteJSON(message); err != nil {
+		c.server.client.log(ctx, LevelDebug, "watch write failed", "error", err)
+		c.conn.Close()
+	}
+}
+
+func (c *watchConn) ping(ctx context.Context) {
+	ticker := time.NewTicker(watchPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(watchWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newWatchServer returns a server resolving every video to a player response whose URLs
+// expire in expiresIn seconds.
+func newWatchServer(t *testing.T, expiresIn string) (*Server, *innertubeServer) {
+	innertube := newInnertubeServer(t, `{
+		"responseContext": {},
+		"playabilityStatus": {"status": "OK"},
+		"streamingData": {"expiresInSeconds": "`+expiresIn+`", "adaptiveFormats": [
+			{"itag": 251, "url": "https://media.example.com/251", "mimeType": "audio/webm; codecs=\"opus\"", "bitrate": 160000}
+		]}
+	}`)
+	client := NewClient()
+	client.BaseURL = innertube.URL
+	return NewServer(client), innertube
+}
+
+func dialWatch(t *testing.T, server *httptest.Server, header http.Header, subprotocols ...string) (*websocket.Conn, *http.Response, error) {
+	t.Helper()
+	dialer := websocket.Dialer{Subprotocols: subprotocols, HandshakeTimeout: 5 * time.Second}
+	conn, response, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/v1/watch", header)
+	if conn != nil {
+		t.Cleanup(func() { conn.Close() })
+	}
+	return conn, response, err
+}
+
+// readWatchMessage returns the next message of the video, skipping the others.
+func readWatchMessage(t *testing.T, conn *websocket.Conn, videoID string) WatchMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var message WatchMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			t.Fatalf("no message of %q: %v", videoID, err)
+		}
+		if message.VideoId == videoID {
+			return message
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	// The URLs expire in 2s, so they are refreshed after a second.
+	server, innertube := newWatchServer(t, "2")
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	conn, _, err := dialWatch(t, httpServer, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn.WriteJSON(WatchRequest{Type: "subscribe", VideoId: "dQw4w9WgXcQ"})
+	first := readWatchMessage(t, conn, "dQw4w9WgXcQ")
+	if first.Type != "descriptor" || first.Descriptor.Streams.Audio == nil || first.Descriptor.ExpiresAt == nil {
+		t.Fatalf("first message %+v, want a descriptor with the audio stream", first)
+	}
+	refreshed := readWatchMessage(t, conn, "dQw4w9WgXcQ")
+	if refreshed.Type != "descriptor" || !refreshed.Descriptor.ExpiresAt.After(*first.Descriptor.ExpiresAt) {
+		t.Fatalf("refreshed message %+v, want a descriptor expiring after %s", refreshed, first.Descriptor.ExpiresAt)
+	}
+	if requests := len(innertube.received()); requests < 2 {
+		t.Errorf("resolved the video %d times, want it resolved again", requests)
+	}
+
+	for _, test := range []struct {
+		message string
+		want    int
+	}{
+		{message: `{"type": "subscribe"}`, want: http.StatusBadRequest},
+		{message: `{"type": "subscribe", "videoId": "9bZkp7q19f0", "select": "best-ever"}`, want: http.StatusBadRequest},
+		{message: `{"type": "replay", "videoId": "9bZkp7q19f0"}`, want: http.StatusBadRequest},
+		{message: `not json`, want: http.StatusBadRequest},
+	} {
+		conn.WriteMessage(websocket.TextMessage, []byte(test.message))
+		videoID := ""
+		if strings.Contains(test.message, "9bZkp7q19f0") {
+			videoID = "9bZkp7q19f0"
+		}
+		if message := readWatchMessage(t, conn, videoID); message.Type != "error" || message.Error.Code != test.want {
+			t.Errorf("%s: got %+v, want error %d", test.message, message, test.want)
+		}
+	}
+
+	// No refresh is pushed after unsubscribing.
+	conn.WriteJSON(WatchRequest{Type: "unsubscribe", VideoId: "dQw4w9WgXcQ"})
+	time.Sleep(100 * time.Millisecond)
+	requests := len(innertube.received())
+	time.Sleep(1500 * time.Millisecond)
+	if after := len(innertube.received()); after != requests {
+		t.Errorf("resolved the video %d times after unsubscribing", after-requests)
+	}
+}
+
+func TestWatchSubscriptionLimit(t *testing.T) {
+	server, _ := newWatchServer(t, "21540")
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	conn, _, err := dialWatch(t, httpServer, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < watchMaxSubscriptions; i++ {
+		videoID := fmt.Sprintf("video%d", i)
+		conn.WriteJSON(WatchRequest{Type: "subscribe", VideoId: videoID})
+		if message := readWatchMessage(t, conn, videoID); message.Type != "descriptor" {
+			t.Fatalf("subscription %d: %+v", i+1, message)
+		}
+	}
+
+	conn.WriteJSON(WatchRequest{Type: "subscribe", VideoId: "one-more"})
+	if message := readWatchMessage(t, conn, "one-more"); message.Type != "error" || message.Error.Code != http.StatusTooManyRequests {
+		t.Fatalf("subscription above the limit: %+v, want error 429", message)
+	}
+
+	// Subscribing again to a watched video doesn't count, nor does a freed subscription.
+	conn.WriteJSON(WatchRequest{Type: "subscribe", VideoId: "video0", Select: "maxHeight=720"})
+	if message := readWatchMessage(t, conn, "video0"); message.Type != "descriptor" {
+		t.Fatalf("subscribing again: %+v, want a descriptor", message)
+	}
+	conn.WriteJSON(WatchRequest{Type: "unsubscribe", VideoId: "video1"})
+	time.Sleep(100 * time.Millisecond)
+	conn.WriteJSON(WatchRequest{Type: "subscribe", VideoId: "one-more"})
+	if message := readWatchMessage(t, conn, "one-more"); message.Type != "descriptor" {
+		t.Fatalf("subscription after unsubscribing: %+v, want a descriptor", message)
+	}
+}
+
+func TestWatchHandshake(t *testing.T) {
+	server, _ := newWatchServer(t, "21540")
+	server.Auth = NewAuthenticator([]APIKey{{Key: "key-one", Name: "one"}})
+	server.AllowedOrigins = []string{"https://world.example.com"}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	tests := []struct {
+		name            string
+		header          http.Header
+		subprotocols    []string
+		wantStatus      int
+		wantSubprotocol string
+	}{
+		{name: "native client", header: http.Header{"X-Api-Key": {"key-one"}}, wantStatus: http.StatusSwitchingProtocols},
+		{name: "same origin", header: http.Header{"X-Api-Key": {"key-one"}, "Origin": {httpServer.URL}}, wantStatus: http.StatusSwitchingProtocols},
+		{
+			name:            "allowed origin with subprotocol credential",
+			header:          http.Header{"Origin": {"https://world.example.com"}},
+			subprotocols:    []string{"web-helper", "bearer.key-one"},
+			wantStatus:      http.StatusSwitchingProtocols,
+			wantSubprotocol: "web-helper",
+		},
+		{name: "other origin", header: http.Header{"X-Api-Key": {"key-one"}, "Origin": {"https://evil.example.com"}}, wantStatus: http.StatusForbidden},
+		{name: "no credential", header: http.Header{"Origin": {"https://world.example.com"}}, subprotocols: []string{"web-helper"}, wantStatus: http.StatusUnauthorized},
+		{name: "wrong subprotocol credential", subprotocols: []string{"web-helper", "bearer.key-two"}, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, response, err := dialWatch(t, httpServer, test.header, test.subprotocols...)
+			if response == nil {
+				t.Fatalf("no response: %v", err)
+			}
+			if response.StatusCode != test.wantStatus {
+				t.Fatalf("status %d, want %d: %v", response.StatusCode, test.wantStatus, err)
+			}
+			if protocol := response.Header.Get("Sec-WebSocket-Protocol"); protocol != test.wantSubprotocol {
+				t.Errorf("selected subprotocol %q, want %q", protocol, test.wantSubprotocol)
+			}
+		})
+	}
+}
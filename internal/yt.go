@@ -2,13 +2,14 @@ package internal
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"time"
+	"io"
+	"sync"
 )
 
+// PlayerResponse is the response of the player endpoint.
 type PlayerResponse struct {
 	ResponseContext   ResponseContext   `json:"responseContext"`
 	PlayabilityStatus PlayabilityStatus `json:"playabilityStatus"`
@@ -16,6 +17,7 @@ type PlayerResponse struct {
 	PlaybackTracking  PlaybackTracking  `json:"playbackTracking"`
 	VideoDetails      VideoDetails      `json:"videoDetails"`
 	PlayerConfig      PlayerConfig      `json:"playerConfig"`
+	Captions          Captions          `json:"captions"`
 }
 
 type ResponseContext struct {
@@ -24,9 +26,32 @@ type ResponseContext struct {
 
 type PlayabilityStatus struct {
 	Status          string `json:"status"`
+	Reason          string `json:"reason,omitempty"`
 	PlayableInEmbed bool   `json:"playableInEmbed"`
 }
 
+// PlayabilityError reports a video which can't be played, by the status of its player
+// response: LOGIN_REQUIRED, UNPLAYABLE, ERROR, LIVE_STREAM_OFFLINE...
+type PlayabilityError struct {
+	Status string
+	Reason string
+}
+
+func (e *PlayabilityError) Error() string {
+	if e.Reason == "" {
+		return "video not playable: " + e.Status
+	}
+	return fmt.Sprintf("video not playable: %s: %s", e.Status, e.Reason)
+}
+
+// Err returns a *PlayabilityError unless the status is OK.
+func (s PlayabilityStatus) Err() error {
+	if s.Status == "OK" {
+		return nil
+	}
+	return &PlayabilityError{Status: s.Status, Reason: s.Reason}
+}
+
 type ColorInfo struct {
 	Primaries               string `json:"primaries,omitempty"`
 	TransferCharacteristics string `json:"transferCharacteristics,omitempty"`
@@ -56,36 +81,57 @@ type Format struct {
 	ApproxDurationMs string    `json:"approxDurationMs"`
 	AudioSampleRate  string    `json:"audioSampleRate,omitempty"`
 	AudioChannels    int       `json:"audioChannels,omitempty"`
+	SpatialAudioType string    `json:"spatialAudioType,omitempty"`
 	HighReplication  bool      `json:"highReplication,omitempty"`
 	ColorInfo        ColorInfo `json:"colorInfo,omitempty"`
 	InitRange        Range     `json:"initRange,omitempty"`
 	IndexRange       Range     `json:"indexRange,omitempty"`
+
+	DynamicRange DynamicRange `json:"dynamicRange,omitempty"`
+	ToneMapping  *ToneMapping `json:"toneMapping,omitempty"`
 }
 
 type AdaptiveFormat struct {
-	Itag             int       `json:"itag"`
-	URL              string    `json:"url"`
-	MimeType         string    `json:"mimeType"`
-	Bitrate          int       `json:"bitrate"`
-	Width            int       `json:"width"`
-	Height           int       `json:"height"`
-	InitRange        Range     `json:"initRange,omitempty"`
-	IndexRange       Range     `json:"indexRange,omitempty"`
-	LastModified     string    `json:"lastModified"`
-	ContentLength    string    `json:"contentLength"`
-	Quality          string    `json:"quality"`
-	FPS              int       `json:"fps"`
-	QualityLabel     string    `json:"qualityLabel"`
-	ProjectionType   string    `json:"projectionType"`
-	AverageBitrate   int       `json:"averageBitrate"`
-	ColorInfo        ColorInfo `json:"colorInfo,omitempty"`
-	ApproxDurationMs string    `json:"approxDurationMs"`
+	Itag             int         `json:"itag"`
+	URL              string      `json:"url"`
+	MimeType         string      `json:"mimeType"`
+	Bitrate          int         `json:"bitrate"`
+	Width            int         `json:"width"`
+	Height           int         `json:"height"`
+	InitRange        Range       `json:"initRange,omitempty"`
+	IndexRange       Range       `json:"indexRange,omitempty"`
+	LastModified     string      `json:"lastModified"`
+	ContentLength    string      `json:"contentLength"`
+	Quality          string      `json:"quality"`
+	FPS              int         `json:"fps"`
+	QualityLabel     string      `json:"qualityLabel"`
+	ProjectionType   string      `json:"projectionType"`
+	AverageBitrate   int         `json:"averageBitrate"`
+	ColorInfo        ColorInfo   `json:"colorInfo,omitempty"`
+	ApproxDurationMs string      `json:"approxDurationMs"`
+	AudioQuality     string      `json:"audioQuality,omitempty"`
+	AudioSampleRate  string      `json:"audioSampleRate,omitempty"`
+	AudioChannels    int         `json:"audioChannels,omitempty"`
+	LoudnessDb       float64     `json:"loudnessDb,omitempty"`
+	SpatialAudioType string      `json:"spatialAudioType,omitempty"`
+	AudioTrack       *AudioTrack `json:"audioTrack,omitempty"`
+
+	DynamicRange DynamicRange `json:"dynamicRange,omitempty"`
+	ToneMapping  *ToneMapping `json:"toneMapping,omitempty"`
+}
+
+type AudioTrack struct {
+	Id             string `json:"id"`
+	DisplayName    string `json:"displayName"`
+	AudioIsDefault bool   `json:"audioIsDefault"`
 }
 
 type StreamingData struct {
 	ExpiresInSeconds string           `json:"expiresInSeconds"`
 	Formats          []Format         `json:"formats"`
 	AdaptiveFormats  []AdaptiveFormat `json:"adaptiveFormats"`
+	HlsManifestUrl   string           `json:"hlsManifestUrl,omitempty"`
+	DashManifestUrl  string           `json:"dashManifestUrl,omitempty"`
 }
 
 type TrackingUrlHeader struct {
@@ -131,6 +177,34 @@ type VideoDetails struct {
 	IsPrivate         bool          `json:"isPrivate"`
 	IsUnpluggedCorpus bool          `json:"isUnpluggedCorpus"`
 	IsLiveContent     bool          `json:"isLiveContent"`
+	IsLive            bool          `json:"isLive,omitempty"`
+	IsUpcoming        bool          `json:"isUpcoming,omitempty"`
+}
+
+type Text struct {
+	SimpleText string    `json:"simpleText,omitempty"`
+	Runs       []TextRun `json:"runs,omitempty"`
+}
+
+type TextRun struct {
+	Text string `json:"text"`
+}
+
+type CaptionTrack struct {
+	BaseUrl        string `json:"baseUrl"`
+	Name           Text   `json:"name"`
+	VssId          string `json:"vssId"`
+	LanguageCode   string `json:"languageCode"`
+	Kind           string `json:"kind,omitempty"`
+	IsTranslatable bool   `json:"isTranslatable"`
+}
+
+type CaptionTracklist struct {
+	CaptionTracks []CaptionTrack `json:"captionTracks"`
+}
+
+type Captions struct {
+	PlayerCaptionsTracklistRenderer CaptionTracklist `json:"playerCaptionsTracklistRenderer"`
 }
 
 type AudioConfig struct {
@@ -284,60 +358,40 @@ type PlayerConfig struct {
 	ExoPlayerConfig ExoplayerConfig `json:"exoPlayerConfig"`
 }
 
+// GetPlayerResponse requests the player response of the video with the DefaultClient.
 func GetPlayerResponse(videoID string) (*PlayerResponse, error) {
-	url := "https://www.youtube.com/youtubei/v1/player"
-	requestBody := fmt.Sprintf(`{
-		"videoId": "%s",
-		"context": {
-			"client": {
-				"clientName": "ANDROID_TESTSUITE",
-				"clientVersion": "1.9",
-				"androidSdkVersion": 30,
-				"hl": "en",
-				"gl": "US",
-				"utcOffsetMinutes": 0
-			}
-		}
-	}`, videoID)
-
-	request, err := http.NewRequest("POST", url, bytes.NewBufferString(requestBody))
-	if err != nil {
-		return nil, err
-	}
-
-	request.Header.Set("Content-Type", "application/json")
-	request.Header.Set("User-Agent", "com.google.android.youtube/17.36.4 (Linux; U; Android 12; GB) gzip")
-
-	client := &http.Client{Timeout: time.Second * 10}
-	response, err := client.Do(request)
-	if err != nil {
-		return nil, err
-	}
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("request failed with status code: %d", response.StatusCode)
-	}
-
-	responseBody, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	playerResponse, err := parsePlayerResponse(responseBody)
-	if err != nil {
-		return nil, err
-	}
-
-	return playerResponse, nil
+	return DefaultClient.GetPlayerResponse(context.Background(), videoID)
 }
 
 func parsePlayerResponse(responseBody []byte) (*PlayerResponse, error) {
 	playerResponse := &PlayerResponse{}
-	err := json.Unmarshal(responseBody, &playerResponse)
+	err := json.Unmarshal(responseBody, playerResponse)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse player response: %v", err)
 	}
 
+	playerResponse.StreamingData.classifyColor()
+
 	return playerResponse, nil
 }
+
+// bodyBuffers are reused to read the response bodies. Decoding copies everything it keeps,
+// as does the cache encoding, so a buffer can be reused as soon as the response is decoded.
+var bodyBuffers = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// readPooled reads the body into a pooled buffer and passes its bytes to use, which must
+// not keep them.
+func readPooled(body io.Reader, use func(body []byte) error) error {
+	buffer := bodyBuffers.Get().(*bytes.Buffer)
+	buffer.Reset()
+	defer bodyBuffers.Put(buffer)
+
+	if _, err := buffer.ReadFrom(body); err != nil {
+		return err
+	}
+	return use(buffer.Bytes())
+}
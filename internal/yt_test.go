@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func readPlayerResponseFixture(b *testing.B) []byte {
+	body, err := os.ReadFile("testdata/player_response.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	return body
+}
+
+func BenchmarkParsePlayerResponse(b *testing.B) {
+	body := readPlayerResponseFixture(b)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(body)))
+	for i := 0; i < b.N; i++ {
+		if _, err := parsePlayerResponse(body); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkGetPlayerResponse compares reading the response body with io.ReadAll and into a
+// pooled buffer. Both paths share the client and parse the body and keep the session the
+// same way.
+func BenchmarkGetPlayerResponse(b *testing.B) {
+	body := readPlayerResponseFixture(b)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.BaseURL = server.URL
+	ctx := context.Background()
+	request := client.newPlayerRequest("dQw4w9WgXcQ")
+
+	get := func(b *testing.B, read func(body io.Reader, parse func(body []byte) error) error) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var playerResponse *PlayerResponse
+			err := client.call(ctx, "player", request, func(body io.Reader) error {
+				return read(body, func(body []byte) (err error) {
+					playerResponse, err = parsePlayerResponse(body)
+					return err
+				})
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+			client.keepSession(ctx, playerResponse)
+		}
+	}
+
+	b.Run("readAll", func(b *testing.B) {
+		get(b, func(body io.Reader, parse func(body []byte) error) error {
+			var responseBody []byte
+			if err := readAll(&responseBody)(body); err != nil {
+				return err
+			}
+			return parse(responseBody)
+		})
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		get(b, readPooled)
+	})
+}